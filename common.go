@@ -8,23 +8,39 @@
 package common
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
-	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	properties "github.com/dmotylev/goproperties"
+	"golang.org/x/text/encoding/unicode"
 )
 
 const (
@@ -44,10 +60,19 @@ const (
 	appData                 = "APPDATA"
 	GaugePropertiesFile     = "gauge.properties"
 	NightlyDatelayout       = "2006-01-02"
+	defaultURLTimeout       = 30 * time.Second
+	copyBufferSize          = 32 * 1024
+	maxLineSize             = 1024 * 1024
+	watchPollInterval       = 100 * time.Millisecond
+	watchDebounce           = 300 * time.Millisecond
+	progressInterval        = 250 * time.Millisecond
+	killWaitDelay           = 500 * time.Millisecond
+	projectNotFoundMessage  = "Failed to find Gauge project directory. Missing %s file."
 )
 
 const (
 	GaugeProjectRootEnv      = "GAUGE_PROJECT_ROOT"
+	GaugeRootEnv             = "GAUGE_ROOT" // specifies the installation prefix of gauge if installed to a non-standard location
 	GaugeHome                = "GAUGE_HOME" //specifies the plugin installation path if installs to non-standard location
 	GaugePortEnvName         = "GAUGE_PORT" // user specifies this to use a specific port
 	GaugeInternalPortEnvName = "GAUGE_INTERNAL_PORT"
@@ -56,6 +81,35 @@ const (
 	GaugeDebugOptsEnv        = "GAUGE_DEBUG_OPTS" //specify the debug options to be used while launching the runner
 )
 
+var (
+	// ErrProjectNotFound is wrapped by the errors returned when a Gauge project root cannot be found
+	ErrProjectNotFound = errors.New("gauge project not found")
+	// ErrFileNotFound is wrapped by the errors returned when a file to be read does not exist
+	ErrFileNotFound = errors.New("file not found")
+	// ErrPluginNotInstalled is wrapped by the errors returned when a plugin or plugin version is not installed
+	ErrPluginNotInstalled = errors.New("plugin not installed")
+	// ErrCommandTimeout is wrapped by the error returned when a command does not finish within its timeout
+	ErrCommandTimeout = errors.New("command timed out")
+)
+
+// wrappedError keeps the message of an error while allowing errors.Is to match the error it wraps
+type wrappedError struct {
+	message string
+	cause   error
+}
+
+func (e *wrappedError) Error() string {
+	return e.message
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.cause
+}
+
+func wrapError(cause error, format string, args ...interface{}) error {
+	return &wrappedError{message: fmt.Sprintf(format, args...), cause: cause}
+}
+
 // Property represents a single property in the properties file
 type Property struct {
 	Name         string
@@ -65,38 +119,204 @@ type Property struct {
 
 // GetProjectRoot returns the Gauge project root
 // A project root is where a manifest.json files exists
-// this routine keeps going upwards searching for manifest.json
+// If GAUGE_PROJECT_ROOT points to such a directory it is used,
+// otherwise this routine keeps going upwards searching for manifest.json
 func GetProjectRoot() (string, error) {
+	if root := os.Getenv(GaugeProjectRootEnv); root != "" && FileExists(filepath.Join(root, ManifestFile)) {
+		return filepath.Abs(root)
+	}
 	pwd, err := os.Getwd()
 	if err != nil {
-		return "", fmt.Errorf("Failed to find Gauge project root directory. Missing manifest.json file: %s\n", err.Error())
+		return "", wrapError(ErrProjectNotFound, projectNotFoundMessage+" %s", ManifestFile, err.Error())
 	}
-	return findManifestInPath(pwd)
+	return GetProjectRootFromDir(pwd)
+}
+
+// GetProjectRootFromDir returns the Gauge project root by searching upwards from startDir for manifest.json
+func GetProjectRootFromDir(startDir string) (string, error) {
+	return FindRootWithMarker(startDir, ManifestFile)
 }
 
-func findManifestInPath(pwd string) (string, error) {
-	wd, err := filepath.Abs(pwd)
+// FindRootWithMarker returns the first directory, starting from startDir and going upwards,
+// which contains any of the given marker files or directories
+func FindRootWithMarker(startDir string, markers ...string) (string, error) {
+	missing := strings.Join(markers, ", ")
+	wd, err := filepath.Abs(startDir)
 	if err != nil {
-		return "", fmt.Errorf("Failed to find Gauge project directory. Missing manifest.json file: %s", err)
+		return "", wrapError(ErrProjectNotFound, projectNotFoundMessage+" %s", missing, err.Error())
 	}
-	manifestExists := func(dir string) bool {
-		return FileExists(filepath.Join(dir, ManifestFile))
+	markerExists := func(dir string) bool {
+		for _, marker := range markers {
+			if FileExists(filepath.Join(dir, marker)) {
+				return true
+			}
+		}
+		return false
 	}
 	dir := wd
 
 	for {
-		if manifestExists(dir) {
+		if markerExists(dir) {
 			return dir, nil
 		}
 		if dir == filepath.Clean(fmt.Sprintf("%c", os.PathSeparator)) || dir == "" {
-			return "", fmt.Errorf("Failed to find Gauge project directory. Missing manifest.json file.")
+			return "", wrapError(ErrProjectNotFound, projectNotFoundMessage, missing)
 		}
 		oldDir := dir
 		dir = filepath.Clean(fmt.Sprintf("%s%c..", dir, os.PathSeparator))
 		if dir == oldDir {
-			return "", fmt.Errorf("Failed to find Gauge project directory. Missing manifest.json file.")
+			return "", wrapError(ErrProjectNotFound, projectNotFoundMessage, missing)
+		}
+	}
+}
+
+type projectRootCacheEntry struct {
+	root            string
+	manifestModTime time.Time
+}
+
+var projectRootCache = map[string]projectRootCacheEntry{}
+var projectRootCacheMutex = sync.Mutex{}
+
+// GetProjectRootCached returns the Gauge project root for startDir like GetProjectRootFromDir, caching the result.
+// A cached root is discarded when its manifest.json is modified or removed.
+func GetProjectRootCached(startDir string) (string, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", wrapError(ErrProjectNotFound, projectNotFoundMessage+" %s", ManifestFile, err.Error())
+	}
+	projectRootCacheMutex.Lock()
+	defer projectRootCacheMutex.Unlock()
+	if entry, ok := projectRootCache[dir]; ok {
+		if fi, err := os.Stat(filepath.Join(entry.root, ManifestFile)); err == nil && fi.ModTime().Equal(entry.manifestModTime) {
+			return entry.root, nil
+		}
+		delete(projectRootCache, dir)
+	}
+	root, err := GetProjectRootFromDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(filepath.Join(root, ManifestFile)); err == nil {
+		projectRootCache[dir] = projectRootCacheEntry{root: root, manifestModTime: fi.ModTime()}
+	}
+	return root, nil
+}
+
+// ClearProjectRootCache removes all the project roots cached by GetProjectRootCached
+func ClearProjectRootCache() {
+	projectRootCacheMutex.Lock()
+	defer projectRootCacheMutex.Unlock()
+	projectRootCache = map[string]projectRootCacheEntry{}
+}
+
+// Manifest represents the contents of a Gauge project's manifest.json
+type Manifest struct {
+	Language string
+	Plugins  []string
+	// Extra holds the fields of manifest.json which are not modelled above, so that they are preserved on write
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON reads the known fields of the manifest and keeps the rest in Extra
+func (m *Manifest) UnmarshalJSON(data []byte) error {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if language, ok := fields["Language"]; ok {
+		if err := json.Unmarshal(language, &m.Language); err != nil {
+			return fmt.Errorf("Invalid Language: %s", err.Error())
+		}
+		delete(fields, "Language")
+	}
+	if plugins, ok := fields["Plugins"]; ok {
+		if err := json.Unmarshal(plugins, &m.Plugins); err != nil {
+			return fmt.Errorf("Invalid Plugins: %s", err.Error())
+		}
+		delete(fields, "Plugins")
+	}
+	m.Extra = fields
+	return nil
+}
+
+// MarshalJSON writes the known fields of the manifest along with the fields in Extra
+func (m Manifest) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(m.Extra)+2)
+	for k, v := range m.Extra {
+		fields[k] = v
+	}
+	fields["Language"] = m.Language
+	plugins := m.Plugins
+	if plugins == nil {
+		plugins = []string{}
+	}
+	fields["Plugins"] = plugins
+	return json.Marshal(fields)
+}
+
+// ReadManifest reads and parses the manifest.json in the given project root
+func ReadManifest(projectRoot string) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := ReadJSONFile(filepath.Join(projectRoot, ManifestFile), manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// WriteManifest writes the manifest to manifest.json in the given project root
+func WriteManifest(projectRoot string, m *Manifest) error {
+	return WriteJSONFile(filepath.Join(projectRoot, ManifestFile), m)
+}
+
+// AddPluginToManifest adds the plugin to the plugins in the manifest.json of the project, if not already present
+func AddPluginToManifest(projectRoot, plugin string) error {
+	manifest, err := ReadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+	for _, p := range manifest.Plugins {
+		if p == plugin {
+			return nil
+		}
+	}
+	manifest.Plugins = append(manifest.Plugins, plugin)
+	return WriteManifest(projectRoot, manifest)
+}
+
+// RemovePluginFromManifest removes the plugin from the plugins in the manifest.json of the project, if present
+func RemovePluginFromManifest(projectRoot, plugin string) error {
+	manifest, err := ReadManifest(projectRoot)
+	if err != nil {
+		return err
+	}
+	plugins := []string{}
+	for _, p := range manifest.Plugins {
+		if p != plugin {
+			plugins = append(plugins, p)
 		}
 	}
+	if len(plugins) == len(manifest.Plugins) {
+		return nil
+	}
+	manifest.Plugins = plugins
+	return WriteManifest(projectRoot, manifest)
+}
+
+// GetProjectLanguage returns the language in the manifest.json of the project
+func GetProjectLanguage() (string, error) {
+	projectRoot, err := GetProjectRoot()
+	if err != nil {
+		return "", err
+	}
+	manifest, err := ReadManifest(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	if manifest.Language == "" {
+		return "", fmt.Errorf("Language is not set in %s", filepath.Join(projectRoot, ManifestFile))
+	}
+	return manifest.Language, nil
 }
 
 // GetDirInProject returns the path of a particular directory in a Gauge project
@@ -120,13 +340,91 @@ func GetProjectRootFromSpecPath(specPath string) (string, error) {
 		dir, _ := filepath.Split(specPath)
 		fullPath, pathErr := filepath.Abs(dir)
 		if pathErr != nil {
-			return "", fmt.Errorf("Unable to get absolute path to specifications. %s", err)
+			return "", wrapError(ErrProjectNotFound, "Unable to get absolute path to specifications. %s", pathErr.Error())
 		}
-		return findManifestInPath(fullPath)
+		return GetProjectRootFromDir(fullPath)
 	}
 	return projectRoot, err
 }
 
+// RelPathToProject returns the path of the spec relative to the project root, using forward slashes
+func RelPathToProject(specPath string) (string, error) {
+	projectRoot, err := GetProjectRootFromSpecPath(specPath)
+	if err != nil {
+		return "", err
+	}
+	absSpecPath, err := filepath.Abs(specPath)
+	if err != nil {
+		return "", fmt.Errorf("Unable to get absolute path to %s. %s", specPath, err.Error())
+	}
+	rel, ok := relInDir(projectRoot, absSpecPath)
+	if !ok {
+		return "", fmt.Errorf("%s is not inside the project %s", specPath, projectRoot)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// PathToFileURL returns the file:// URL of the given path, percent-encoding it as required.
+// Windows drive letters become file:///C:/... and UNC paths file://server/share/...
+func PathToFileURL(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("Unable to get absolute path to %s. %s", path, err.Error())
+	}
+	slashed := filepath.ToSlash(absPath)
+	u := &url.URL{Scheme: "file", Path: slashed}
+	if strings.HasPrefix(slashed, "//") {
+		hostAndPath := strings.SplitN(slashed[2:], "/", 2)
+		u.Host, u.Path = hostAndPath[0], "/"
+		if len(hostAndPath) == 2 {
+			u.Path += hostAndPath[1]
+		}
+	} else if !strings.HasPrefix(slashed, "/") {
+		u.Path = "/" + slashed
+	}
+	return u.String(), nil
+}
+
+// FileURLToPath returns the path of the given file:// URL, decoding percent-encoded characters
+func FileURLToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("Invalid file URL %s. %s", uri, err.Error())
+	}
+	if !strings.EqualFold(u.Scheme, "file") {
+		return "", fmt.Errorf("Invalid file URL %s. Scheme must be file", uri)
+	}
+	path := u.Path
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		if !isWindows() {
+			return "", fmt.Errorf("Invalid file URL %s. UNC paths are only supported on Windows", uri)
+		}
+		return filepath.FromSlash("//" + u.Host + path), nil
+	}
+	if isWindows() && len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// IsInProject checks if the given path lies within the project root.
+// Paths are compared case-insensitively on Windows and macOS.
+func IsInProject(projectRoot, path string) bool {
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if isCaseInsensitiveOS() {
+		root, absPath = strings.ToLower(root), strings.ToLower(absPath)
+	}
+	_, ok := relInDir(root, absPath)
+	return ok
+}
+
 // GetDefaultPropertiesFile returns the path of the default.properties file in the default env
 func GetDefaultPropertiesFile() (string, error) {
 	envDir, err := GetDirInProject(EnvDirectoryName, "")
@@ -140,6 +438,67 @@ func GetDefaultPropertiesFile() (string, error) {
 	return defaultEnvFile, nil
 }
 
+// GetDefaultPropertiesFiles returns the sorted paths of all the .properties files in the default env
+func GetDefaultPropertiesFiles() ([]string, error) {
+	return envPropertiesFiles(DefaultEnvDir)
+}
+
+// LoadEnvProperties returns the properties of all the .properties files in the given environment of the project.
+// Files are loaded in lexical order, a property defined in a later file overrides an earlier one.
+func LoadEnvProperties(envName string) (properties.Properties, error) {
+	files, err := envPropertiesFiles(envName)
+	if err != nil {
+		return nil, err
+	}
+	config := make(properties.Properties)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		err = config.Load(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load properties from %s: %s", file, err.Error())
+		}
+	}
+	return config, nil
+}
+
+// ListEnvironments returns the sorted names of the environments in the env directory of the project.
+// An error is returned if the env directory or the default environment is missing.
+func ListEnvironments() ([]string, error) {
+	envDir, err := GetDirInProject(EnvDirectoryName, "")
+	if err != nil {
+		return nil, err
+	}
+	if defaultEnv := filepath.Join(envDir, DefaultEnvDir); !DirExists(defaultEnv) {
+		return nil, fmt.Errorf("Default environment does not exist: %s", defaultEnv)
+	}
+	return listSubDirectories(envDir)
+}
+
+// envPropertiesFiles returns the sorted paths of the .properties files in the given environment of the project
+func envPropertiesFiles(envName string) ([]string, error) {
+	if !isPathElement(envName) {
+		return nil, fmt.Errorf("Invalid environment name %s", envName)
+	}
+	envDir, err := GetDirInProject(EnvDirectoryName, "")
+	if err != nil {
+		return nil, err
+	}
+	envNameDir := filepath.Join(envDir, envName)
+	if !DirExists(envNameDir) {
+		return nil, fmt.Errorf("Environment %s does not exist: %s", envName, envNameDir)
+	}
+	files, err := filepath.Glob(filepath.Join(envNameDir, "*.properties"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // AppendProperties appends the given properties to the end of the properties file.
 func AppendProperties(propertiesFile string, properties ...*Property) error {
 	file, err := os.OpenFile(propertiesFile, os.O_RDWR|os.O_APPEND, NewFilePermissions)
@@ -152,10 +511,116 @@ func AppendProperties(propertiesFile string, properties ...*Property) error {
 	return file.Close()
 }
 
-// FindFilesInDir returns a list of files for which isValidFile func returns true
+// AppendPropertiesAtomic appends the given properties to the end of the properties file like AppendProperties,
+// but writes the new contents to a temporary file which then replaces the properties file, so an interrupted
+// write cannot leave a partial property behind. If rejectDuplicates is true, an error is returned and nothing is
+// written when a property is already defined in the file or given more than once.
+func AppendPropertiesAtomic(propertiesFile string, rejectDuplicates bool, properties ...*Property) error {
+	fi, err := os.Stat(propertiesFile)
+	if err != nil {
+		return err
+	}
+	contents, err := os.ReadFile(propertiesFile)
+	if err != nil {
+		return fmt.Errorf("Failed to read the file %s.", propertiesFile)
+	}
+	if rejectDuplicates {
+		existing := make(map[string]bool)
+		for _, line := range propertyLines(strings.Split(string(contents), "\n")) {
+			if line.property {
+				existing[line.key] = true
+			}
+		}
+		for _, property := range properties {
+			if existing[property.Name] {
+				return fmt.Errorf("Property %s already exists in %s", property.Name, propertiesFile)
+			}
+			existing[property.Name] = true
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(contents)
+	for _, property := range properties {
+		buf.WriteString(fmt.Sprintf("\n%s\n", property.String()))
+	}
+	return writeFileAtomic(propertiesFile, buf.Bytes(), fi.Mode().Perm())
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path and renames it to path
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", path, err.Error())
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed to write to '%s': %s", path, err.Error())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed to write to '%s': %s", path, err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", path, err.Error())
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("Failed to set permissions of '%s': %s", path, err.Error())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", path, err.Error())
+	}
+	return nil
+}
+
+// ReadProperty returns the property with the given name from the properties file,
+// along with the comment lines immediately preceding it
+func ReadProperty(propertiesFile, name string) (*Property, error) {
+	config, err := properties.Load(propertiesFile)
+	if err != nil {
+		return nil, err
+	}
+	value, found := config[name]
+	if !found {
+		return nil, fmt.Errorf("Property %s not found in %s", name, propertiesFile)
+	}
+	lines, err := ReadFileLines(propertiesFile)
+	if err != nil {
+		return nil, err
+	}
+	var comments []string
+	for _, line := range propertyLines(lines) {
+		if line.comment {
+			comment := strings.TrimSpace(line.lines[0])
+			comments = append(comments, strings.TrimSpace(comment[1:]))
+			continue
+		}
+		if line.property && line.key == name {
+			break
+		}
+		comments = nil
+	}
+	return &Property{Name: name, Comment: strings.Join(comments, "\n"), DefaultValue: value}, nil
+}
+
+func propertyKey(line string) string {
+	if i := strings.IndexAny(line, "=: \t"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// FindFilesInDir returns a sorted list of files for which isValidFile func returns true
 func FindFilesInDir(dirPath string, isValidFile func(path string) bool, shouldSkip func(path string, f os.FileInfo) bool) []string {
+	files, _ := FindFilesInDirWithError(dirPath, isValidFile, shouldSkip)
+	return files
+}
+
+// FindFilesInDirWithError returns a sorted list of files for which isValidFile func returns true.
+// If walking the directory fails, the files found so far are returned along with the error.
+func FindFilesInDirWithError(dirPath string, isValidFile func(path string) bool, shouldSkip func(path string, f os.FileInfo) bool) ([]string, error) {
 	files := []string{}
-	filepath.Walk(dirPath, func(path string, f os.FileInfo, err error) error {
+	err := filepath.Walk(dirPath, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -167,9 +632,54 @@ func FindFilesInDir(dirPath string, isValidFile func(path string) bool, shouldSk
 		}
 		return nil
 	})
+	sort.Strings(files)
+	return files, err
+}
+
+// FindFilesInDirWithDepth returns a sorted list of files for which isValidFile func returns true,
+// descending at most maxDepth directories below dirPath. A maxDepth of 0 only looks at dirPath itself.
+func FindFilesInDirWithDepth(dirPath string, maxDepth int, isValidFile func(path string) bool, shouldSkip func(path string, f os.FileInfo) bool) []string {
+	files, _ := FindFilesInDirWithError(dirPath, isValidFile, func(path string, f os.FileInfo) bool {
+		if shouldSkip(path, f) {
+			return true
+		}
+		if !f.IsDir() {
+			return false
+		}
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil || rel == "." {
+			return false
+		}
+		return len(strings.Split(rel, string(os.PathSeparator))) > maxDepth
+	})
 	return files
 }
 
+// FindFilesByPattern returns a sorted list of files whose base name matches any of the given glob patterns.
+// Hidden directories below dirPath are skipped.
+func FindFilesByPattern(dirPath string, patterns ...string) ([]string, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid file pattern %s: %s", pattern, err.Error())
+		}
+	}
+	return FindFilesInDirWithError(dirPath, func(path string) bool {
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+				return true
+			}
+		}
+		return false
+	}, func(path string, f os.FileInfo) bool {
+		// the directory being searched is never skipped, even when it is hidden
+		return path != dirPath && isHiddenDir(path, f)
+	})
+}
+
+func isHiddenDir(path string, f os.FileInfo) bool {
+	return f.IsDir() && strings.HasPrefix(f.Name(), ".") && f.Name() != "." && f.Name() != ".."
+}
+
 // GetConfigurationPrefix returns the configuration directory prefix
 // $GAUGE_HOME or $home/.gauge/config
 func GetConfigurationDir() (string, error) {
@@ -199,8 +709,11 @@ func GetConfigurationDir() (string, error) {
 }
 
 // GetInstallationPrefix returns the installation directory prefix
-// /usr or /usr/local
+// bin/.. of the running gauge, /usr or /usr/local
 func GetInstallationPrefix() (string, error) {
+	if prefix, ok := runningExecutablePrefix(); ok {
+		return prefix, nil
+	}
 	var possibleInstallationPrefixes []string
 	if isWindows() {
 		programFilesPath := os.Getenv("PROGRAMFILES")
@@ -221,6 +734,64 @@ func GetInstallationPrefix() (string, error) {
 	return "", fmt.Errorf("Can't find installation files")
 }
 
+var executablePath = os.Executable
+
+// GetExecutableDir returns the directory containing the running executable, after resolving symlinks
+func GetExecutableDir() (string, error) {
+	executable, err := executablePath()
+	if err != nil {
+		return "", fmt.Errorf("Failed to find the running executable. %s", err.Error())
+	}
+	resolved, err := filepath.EvalSymlinks(executable)
+	if err != nil {
+		return "", fmt.Errorf("Failed to resolve the running executable %s. %s", executable, err.Error())
+	}
+	return filepath.Dir(resolved), nil
+}
+
+var executablePrefix string
+var executablePrefixFound bool
+var executablePrefixOnce sync.Once
+
+// runningExecutablePrefix returns the bin/.. directory of the running executable if it is a gauge installation prefix.
+// The prefix is computed once per process.
+func runningExecutablePrefix() (string, bool) {
+	executablePrefixOnce.Do(func() {
+		executableDir, err := GetExecutableDir()
+		if err != nil {
+			return
+		}
+		prefix := filepath.Dir(executableDir)
+		if DirExists(filepath.Join(prefix, "share", ProductName)) {
+			executablePrefix, executablePrefixFound = prefix, true
+		}
+	})
+	return executablePrefix, executablePrefixFound
+}
+
+// GetGaugeExecutablePath returns the absolute path of the gauge executable.
+// $GAUGE_ROOT/bin is looked at first, followed by PATH and the standard installation prefixes.
+func GetGaugeExecutablePath() (string, error) {
+	if root := os.Getenv(GaugeRootEnv); root != "" {
+		executable := filepath.Join(root, "bin", ExecutableName())
+		if FileExists(executable) {
+			return filepath.Abs(executable)
+		}
+	}
+	if executable, err := exec.LookPath(ExecutableName()); err == nil {
+		return filepath.Abs(executable)
+	}
+	prefix, err := GetInstallationPrefix()
+	if err != nil {
+		return "", err
+	}
+	executable := filepath.Join(prefix, "bin", ExecutableName())
+	if !FileExists(executable) {
+		return "", fmt.Errorf("Could not find %s in $%s, PATH or installation prefix %s", ExecutableName(), GaugeRootEnv, prefix)
+	}
+	return executable, nil
+}
+
 // ExecutableName returns the Gauge executable name based on user's OS
 func ExecutableName() string {
 	if isWindows() {
@@ -243,6 +814,82 @@ func GetSkeletonFilePath(filename string) (string, error) {
 	return "", fmt.Errorf("Failed to find the skeleton file: %s", filename)
 }
 
+// InitializeProject creates a new Gauge project for the language in targetDir, which must be empty or missing.
+// The specs and env/default directories and manifest.json are created, along with the example.spec and
+// default.properties skeleton files and the files in the skeleton directory of the language, when present.
+// The paths of the created files are returned.
+func InitializeProject(targetDir, language string) ([]string, error) {
+	return initializeProject(targetDir, language, false)
+}
+
+// InitializeProjectForce creates a new Gauge project like InitializeProject, even if targetDir is not empty.
+// Existing files are overwritten by the skeleton files.
+func InitializeProjectForce(targetDir, language string) ([]string, error) {
+	return initializeProject(targetDir, language, true)
+}
+
+func initializeProject(targetDir, language string, force bool) ([]string, error) {
+	if strings.TrimSpace(language) == "" {
+		return nil, fmt.Errorf("Failed to initialize project. Language is required")
+	}
+	if !force {
+		if entries, err := os.ReadDir(targetDir); err == nil && len(entries) > 0 {
+			return nil, fmt.Errorf("Failed to initialize project. %s is not empty", targetDir)
+		}
+	}
+	specsDir := filepath.Join(targetDir, SpecsDirectoryName)
+	defaultEnvDir := filepath.Join(targetDir, EnvDirectoryName, DefaultEnvDir)
+	for _, dir := range []string{specsDir, defaultEnvDir} {
+		if err := EnsureDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	var created []string
+	skeletonFiles := map[string]string{
+		"example.spec":     filepath.Join(specsDir, "example.spec"),
+		DefaultEnvFileName: filepath.Join(defaultEnvDir, DefaultEnvFileName),
+	}
+	for name, target := range skeletonFiles {
+		skelFile, err := GetSkeletonFilePath(name)
+		if err != nil {
+			continue
+		}
+		if err := CopyFile(skelFile, target); err != nil {
+			return created, err
+		}
+		created = append(created, target)
+	}
+	if languageSkelDir, err := GetSkeletonFilePath(language); err == nil && DirExists(languageSkelDir) {
+		err := filepath.Walk(languageSkelDir, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(languageSkelDir, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(targetDir, rel)
+			if err := EnsureDir(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := CopyFile(path, target); err != nil {
+				return err
+			}
+			created = append(created, target)
+			return nil
+		})
+		if err != nil {
+			return created, fmt.Errorf("Failed to copy %s skeleton files. %s", language, err.Error())
+		}
+	}
+	if err := WriteManifest(targetDir, &Manifest{Language: language}); err != nil {
+		return created, err
+	}
+	created = append(created, filepath.Join(targetDir, ManifestFile))
+	sort.Strings(created)
+	return created, nil
+}
+
 // GetPluginsInstallDir returns the plugin installation directory
 func GetPluginsInstallDir(pluginName string) (string, error) {
 	pluginInstallPrefixes, err := GetPluginInstallPrefixes()
@@ -251,11 +898,11 @@ func GetPluginsInstallDir(pluginName string) (string, error) {
 	}
 
 	for _, prefix := range pluginInstallPrefixes {
-		if SubDirectoryExists(prefix, pluginName) {
+		if SubDirectoryExists(prefix, pluginName) || (isCaseInsensitiveOS() && SubDirectoryExistsFold(prefix, pluginName)) {
 			return prefix, nil
 		}
 	}
-	return "", fmt.Errorf("Plugin '%s' not installed on following locations : %s", pluginName, pluginInstallPrefixes)
+	return "", wrapError(ErrPluginNotInstalled, "Plugin '%s' not installed on following locations : %s", pluginName, pluginInstallPrefixes)
 }
 
 // SubDirectoryExists checks if a dir for given plugin exists in the plugin directory
@@ -273,13 +920,36 @@ func SubDirectoryExists(pluginDir string, pluginName string) bool {
 	return false
 }
 
-// GetPluginInstallPrefixes returns the installation prefix path for the plugins
-func GetPluginInstallPrefixes() ([]string, error) {
-	primaryPluginInstallDir, err := GetPrimaryPluginsInstallDir()
+// SubDirectoryExistsFold checks if a dir for given plugin exists in the plugin directory, ignoring case
+func SubDirectoryExistsFold(pluginDir string, pluginName string) bool {
+	files, err := os.ReadDir(pluginDir)
 	if err != nil {
-		return nil, err
+		return false
 	}
-	return []string{primaryPluginInstallDir}, nil
+
+	for _, f := range files {
+		if strings.EqualFold(f.Name(), pluginName) && f.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPluginInstallPrefixes returns the installation prefix paths for the plugins.
+// The user level plugins dir comes first, followed by the system wide share/gauge/plugins dir if it exists.
+func GetPluginInstallPrefixes() ([]string, error) {
+	primaryPluginInstallDir, err := GetPrimaryPluginsInstallDir()
+	if err != nil {
+		return nil, err
+	}
+	prefixes := []string{primaryPluginInstallDir}
+	if installationPrefix, err := GetInstallationPrefix(); err == nil {
+		systemPluginInstallDir := filepath.Join(installationPrefix, "share", ProductName, Plugins)
+		if DirExists(systemPluginInstallDir) && systemPluginInstallDir != primaryPluginInstallDir {
+			prefixes = append(prefixes, systemPluginInstallDir)
+		}
+	}
+	return prefixes, nil
 }
 
 // GetGaugeHomeDirectory returns GAUGE_HOME. This is where all the plugins are installed
@@ -310,11 +980,204 @@ func GetPrimaryPluginsInstallDir() (string, error) {
 
 // IsPluginInstalled checks if the given Gauge plugin version is installed
 func IsPluginInstalled(name, version string) bool {
+	_, installed := PluginInstallPath(name, version)
+	return installed
+}
+
+// PluginInstallPath returns the installation directory of the given Gauge plugin version and whether it is installed
+func PluginInstallPath(name, version string) (string, bool) {
 	pluginsDir, err := GetPluginsInstallDir(name)
 	if err != nil {
-		return false
+		return "", false
 	}
-	return DirExists(filepath.Join(pluginsDir, name, version))
+	path := filepath.Join(pluginsDir, name, version)
+	return path, DirExists(path)
+}
+
+// UninstallPlugin removes the given version of the plugin from its installation directory
+func UninstallPlugin(name, version string) error {
+	if strings.TrimSpace(name) == "" || strings.TrimSpace(version) == "" {
+		return fmt.Errorf("Plugin name and version are required to uninstall a plugin")
+	}
+	pluginsDir, err := GetPluginsInstallDir(name)
+	if err != nil {
+		return err
+	}
+	versionDir, err := SafeJoin(pluginsDir, filepath.Join(name, version))
+	if err != nil || !isPathElement(name) || !isPathElement(version) {
+		return fmt.Errorf("Invalid plugin '%s' version '%s'", name, version)
+	}
+	if !DirExists(versionDir) {
+		return wrapError(ErrPluginNotInstalled, "Plugin '%s' version '%s' is not installed", name, version)
+	}
+	return os.RemoveAll(versionDir)
+}
+
+// isPathElement checks that name is a single path element which does not refer to the current or parent directory
+func isPathElement(name string) bool {
+	return filepath.Base(name) == name && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// ListInstalledPlugins returns the plugins installed in the primary plugin installation dir, mapped to their sorted versions
+func ListInstalledPlugins() (map[string][]string, error) {
+	pluginsDir, err := GetPrimaryPluginsInstallDir()
+	if err != nil {
+		return nil, err
+	}
+	plugins := make(map[string][]string)
+	pluginDirs, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return plugins, nil
+		}
+		return nil, err
+	}
+	for _, pluginDir := range pluginDirs {
+		if !pluginDir.IsDir() || strings.HasPrefix(pluginDir.Name(), ".") {
+			continue
+		}
+		versions, err := listSubDirectories(filepath.Join(pluginsDir, pluginDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		plugins[pluginDir.Name()] = versions
+	}
+	return plugins, nil
+}
+
+// GetLatestInstalledPluginVersion returns the highest installed version of the given plugin.
+// Version directories which are not semantic versions are ignored.
+func GetLatestInstalledPluginVersion(name string) (string, error) {
+	pluginsDir, err := GetPluginsInstallDir(name)
+	if err != nil {
+		return "", err
+	}
+	versions, err := listSubDirectories(filepath.Join(pluginsDir, name))
+	if err != nil {
+		return "", err
+	}
+	var latest *version
+	for _, v := range versions {
+		parsed, err := parseVersion(v)
+		if err != nil {
+			continue
+		}
+		if latest == nil || parsed.compare(latest) > 0 {
+			latest = parsed
+		}
+	}
+	if latest == nil {
+		return "", wrapError(ErrPluginNotInstalled, "No valid versions of plugin '%s' installed in %s", name, filepath.Join(pluginsDir, name))
+	}
+	return latest.original, nil
+}
+
+// version is a semantic version
+type version struct {
+	major, minor, patch int
+	preRelease          string
+	original            string
+}
+
+func parseVersion(v string) (*version, error) {
+	original := v
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	// nightly builds are versioned like 1.0.0.nightly-2024-01-01, the suffix is build metadata
+	if i := strings.Index(v, ".nightly"); i >= 0 {
+		v = v[:i]
+	}
+	preRelease := ""
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, preRelease = v[:i], v[i+1:]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Invalid version %s", original)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid version %s", original)
+		}
+		numbers[i] = n
+	}
+	return &version{major: numbers[0], minor: numbers[1], patch: numbers[2], preRelease: preRelease, original: original}, nil
+}
+
+// compare returns -1, 0 or 1 if v is lower than, equal to or higher than other
+func (v *version) compare(other *version) int {
+	for _, d := range []int{v.major - other.major, v.minor - other.minor, v.patch - other.patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.preRelease == other.preRelease:
+		return 0
+	case v.preRelease == "":
+		return 1
+	case other.preRelease == "":
+		return -1
+	}
+	return comparePreRelease(v.preRelease, other.preRelease)
+}
+
+// comparePreRelease compares the dot separated identifiers of two pre-release versions as semver does.
+// Numeric identifiers are compared numerically and are lower than alphanumeric ones, and a shorter set of
+// identifiers is lower when all the preceding ones are equal.
+func comparePreRelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// listSubDirectories returns the sorted names of the non hidden directories in dir
+func listSubDirectories(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
 // GetGaugeConfiguration parsed the gauge.properties file from GAUGE_HOME and returns the contents
@@ -334,69 +1197,677 @@ func GetGaugeConfigurationFor(propertiesFileName string) (properties.Properties,
 	if err != nil {
 		return nil, err
 	}
-	return config, nil
-}
+	return config, nil
+}
+
+// GetMergedGaugeConfiguration returns the shared gauge.properties overlaid with the user level
+// gauge.properties from the gauge home directory, if present. User values win over shared ones.
+func GetMergedGaugeConfiguration() (properties.Properties, error) {
+	config, err := GetGaugeConfigurationFor(GaugePropertiesFile)
+	if err != nil {
+		return nil, err
+	}
+	gaugeHome, err := GetGaugeHomeDirectory()
+	if err != nil {
+		return nil, err
+	}
+	userPropertiesFile := filepath.Join(gaugeHome, GaugePropertiesFile)
+	if !FileExists(userPropertiesFile) {
+		return config, nil
+	}
+	userConfig, err := properties.Load(userPropertiesFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load properties from %s: %s", userPropertiesFile, err.Error())
+	}
+	for key, value := range userConfig {
+		config[key] = value
+	}
+	return config, nil
+}
+
+// SaveGaugeConfiguration writes the given properties to the gauge.properties file in the configuration directory.
+// Properties already in the file are updated in place, keeping their order and comments. Properties missing from p
+// are removed and new ones are appended in sorted order.
+func SaveGaugeConfiguration(p properties.Properties) error {
+	configDir, err := GetConfigurationDir()
+	if err != nil {
+		return err
+	}
+	propertiesFile := filepath.Join(configDir, GaugePropertiesFile)
+	var lines []string
+	if FileExists(propertiesFile) {
+		if lines, err = ReadFileLines(propertiesFile); err != nil {
+			return err
+		}
+	}
+	written := make(map[string]bool)
+	var contents []string
+	commentStart := -1
+	for _, line := range propertyLines(lines) {
+		if !line.property {
+			if !line.comment {
+				commentStart = -1
+			} else if commentStart < 0 {
+				commentStart = len(contents)
+			}
+			contents = append(contents, line.lines...)
+			continue
+		}
+		key := line.key
+		value, found := p[key]
+		if !found || written[key] {
+			// drop the comments directly above the removed property along with it
+			if commentStart >= 0 {
+				contents = contents[:commentStart]
+			}
+			commentStart = -1
+			continue
+		}
+		contents = append(contents, formatProperty(key, value))
+		written[key] = true
+		commentStart = -1
+	}
+	var keys []string
+	for key := range p {
+		if !written[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		contents = append(contents, formatProperty(key, p[key]))
+	}
+	if err := EnsureDir(configDir); err != nil {
+		return err
+	}
+	err = os.WriteFile(propertiesFile, []byte(strings.Join(contents, "\n")+"\n"), NewFilePermissions)
+	if os.IsPermission(err) {
+		return fmt.Errorf("Permission denied writing %s. Run with elevated privileges or set %s to a writable directory", propertiesFile, GaugeHome)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", propertiesFile, err.Error())
+	}
+	return nil
+}
+
+// propertyLine is a logical line of a properties file: a blank line, a comment line, or a property along with its
+// continuation lines
+type propertyLine struct {
+	lines    []string
+	comment  bool
+	property bool
+	key      string
+}
+
+// propertyLines splits the lines of a properties file into logical lines, so continuation lines are never
+// mistaken for properties or comments
+func propertyLines(lines []string) []propertyLine {
+	var result []propertyLine
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			result = append(result, propertyLine{lines: lines[i : i+1]})
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!"):
+			result = append(result, propertyLine{lines: lines[i : i+1], comment: true})
+		default:
+			start := i
+			for continuesOnNextLine(lines[i]) && i+1 < len(lines) {
+				i++
+			}
+			entry := lines[start : i+1]
+			result = append(result, propertyLine{lines: entry, property: true, key: loadedPropertyKey(strings.Join(entry, "\n"))})
+		}
+	}
+	return result
+}
+
+// continuesOnNextLine returns true if the properties file line ends with an odd number of backslashes
+func continuesOnNextLine(line string) bool {
+	line = strings.TrimSuffix(line, "\r")
+	return (len(line)-len(strings.TrimRight(line, `\`)))%2 == 1
+}
+
+// loadedPropertyKey returns the key of the property entry as goproperties reads it
+func loadedPropertyKey(entry string) string {
+	p := properties.Properties{}
+	if err := p.Load(strings.NewReader(entry)); err == nil {
+		for key := range p {
+			return key
+		}
+	}
+	return propertyKey(strings.TrimSpace(entry))
+}
+
+// formatProperty returns the properties file line for the key and value, escaped so goproperties reads them back unchanged
+func formatProperty(key, value string) string {
+	return fmt.Sprintf("%s = %s", escapeProperty(key, true), escapeProperty(value, false))
+}
+
+// escapeProperty escapes backslashes, control characters and leading whitespace.
+// Keys also have separators escaped, along with a leading comment character.
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (isKey || i == 0),
+			(r == '=' || r == ':') && isKey,
+			(r == '#' || r == '!') && isKey && i == 0:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// GetStringProperty returns the value of the property, or def if it is not set
+func GetStringProperty(p properties.Properties, key, def string) string {
+	if value, found := p[key]; found {
+		return value
+	}
+	return def
+}
+
+// GetBoolProperty returns the value of the property parsed as a bool, or def if it is not set or not a bool.
+// true/false, yes/no and 1/0 are accepted, ignoring case.
+func GetBoolProperty(p properties.Properties, key string, def bool) bool {
+	value, found := p[key]
+	if !found {
+		return def
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "1":
+		return true
+	case "false", "no", "0":
+		return false
+	}
+	return def
+}
+
+// GetIntProperty returns the value of the property parsed as an int, or def if it is not set or not a number
+func GetIntProperty(p properties.Properties, key string, def int) int {
+	value, found := p[key]
+	if !found {
+		return def
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return def
+	}
+	return i
+}
+
+// ExpandProperties returns a copy of the properties with $VAR and ${VAR} references in the values
+// replaced by the environment variables. Undefined variables expand to an empty string.
+// Values are expanded once, references in the values of the environment variables are kept as is.
+func ExpandProperties(p properties.Properties) properties.Properties {
+	expanded := make(properties.Properties, len(p))
+	for key, value := range p {
+		expanded[key] = os.ExpandEnv(value)
+	}
+	return expanded
+}
+
+// ReadFileContents returns the contents of the file
+func ReadFileContents(file string) (string, error) {
+	if !FileExists(file) {
+		return "", wrapError(ErrFileNotFound, "File %s doesn't exist.", file)
+	}
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read the file %s.", file)
+	}
+	contents, err := decodeContents(bytes)
+	if err != nil {
+		return "", fmt.Errorf("Failed to decode the file %s. %s", file, err.Error())
+	}
+	return contents, nil
+}
+
+// ReadFileContentsNormalized returns the contents of the file with CRLF and CR line endings converted to LF
+func ReadFileContentsNormalized(file string) (string, error) {
+	contents, err := ReadFileContents(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(contents, "\r\n", "\n"), "\r", "\n"), nil
+}
+
+// WriteFileContents writes the contents to the file, creating the parent directories if required
+func WriteFileContents(file, contents string) error {
+	if err := EnsureDir(filepath.Dir(file)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, []byte(contents), NewFilePermissions); err != nil {
+		return fmt.Errorf("Failed to write the file %s. %s", file, err.Error())
+	}
+	return nil
+}
+
+// ReadJSONFile parses the JSON file into v
+func ReadJSONFile(path string, v interface{}) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %s", path, err.Error())
+	}
+	if err := json.Unmarshal(contents, v); err != nil {
+		return fmt.Errorf("Failed to parse %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+// WriteJSONFile writes v to the file as JSON indented with two spaces and a trailing newline,
+// creating the parent directories if required
+func WriteJSONFile(path string, v interface{}) error {
+	contents, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to write %s: %s", path, err.Error())
+	}
+	return WriteFileContents(path, string(contents)+"\n")
+}
+
+// ReadFileLines returns the lines of the file, without the line terminators
+func ReadFileLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the file %s.", file)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, copyBufferSize), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\xef\xbb\xbf")
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read the file %s. %s", file, err.Error())
+	}
+	return lines, nil
+}
+
+// TailFile returns up to the last n lines of the file, without the line terminators.
+// The file is read backwards in chunks, so only the tail of large files is loaded.
+func TailFile(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the file %s.", path)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the file %s. %s", path, err.Error())
+	}
+	lines := []string{}
+	if n <= 0 {
+		return lines, nil
+	}
+
+	var tail []byte
+	offset := fi.Size()
+	for offset > 0 && bytes.Count(bytes.TrimSuffix(tail, []byte("\n")), []byte("\n")) < n {
+		chunkSize := int64(copyBufferSize)
+		if offset < chunkSize {
+			chunkSize = offset
+		}
+		offset -= chunkSize
+		chunk := make([]byte, chunkSize)
+		if _, err := f.ReadAt(chunk, offset); err != nil {
+			return nil, fmt.Errorf("Failed to read the file %s. %s", path, err.Error())
+		}
+		tail = append(chunk, tail...)
+	}
+	tail = bytes.TrimSuffix(tail, []byte("\n"))
+	if len(tail) == 0 {
+		return lines, nil
+	}
+	for _, line := range strings.Split(string(tail), "\n") {
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// WatchFile polls the file for changes to its modification time or size and calls onChange after a change,
+// until the context is cancelled. Changes within watchDebounce of each other result in a single call.
+func WatchFile(ctx context.Context, path string, onChange func()) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("Failed to watch %s. %s", path, err.Error())
+	}
+	last := fi
+	var changedAt time.Time
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			fi, err := os.Stat(path)
+			if err != nil {
+				fi = nil
+			}
+			if !sameFileState(last, fi) {
+				last = fi
+				changedAt = now
+				continue
+			}
+			if !changedAt.IsZero() && now.Sub(changedAt) >= watchDebounce {
+				changedAt = time.Time{}
+				onChange()
+			}
+		}
+	}
+}
+
+func sameFileState(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// ReadFileContentsWithLimit returns the contents of the file, failing if the file is larger than maxBytes
+func ReadFileContentsWithLimit(file string, maxBytes int64) (string, error) {
+	fi, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		return "", wrapError(ErrFileNotFound, "File %s doesn't exist.", file)
+	}
+	if err != nil {
+		return "", err
+	}
+	if fi.Size() > maxBytes {
+		return "", fmt.Errorf("File %s is larger than %d bytes.", file, maxBytes)
+	}
+	return ReadFileContents(file)
+}
+
+// decodeContents converts UTF-16 contents with a byte order mark to UTF-8 and strips the UTF-8 byte order mark.
+// Contents without a recognized byte order mark are treated as UTF-8.
+func decodeContents(b []byte) (string, error) {
+	var endianness unicode.Endianness
+	switch {
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		endianness = unicode.LittleEndian
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		endianness = unicode.BigEndian
+	default:
+		return strings.TrimLeft(string(b), "\xef\xbb\xbf"), nil
+	}
+	decoded, err := unicode.UTF16(endianness, unicode.ExpectBOM).NewDecoder().Bytes(b)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// FileChecksum returns the lowercase hex encoded SHA256 digest of the given file
+func FileChecksum(path string) (string, error) {
+	return FileHash(path, sha256.New())
+}
+
+// FileMD5 returns the lowercase hex encoded MD5 digest of the given file
+func FileMD5(path string) (string, error) {
+	return FileHash(path, md5.New())
+}
+
+// FileSHA1 returns the lowercase hex encoded SHA1 digest of the given file
+func FileSHA1(path string) (string, error) {
+	return FileHash(path, sha1.New())
+}
+
+// FileHash streams the given file through h and returns the lowercase hex encoded digest
+func FileHash(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("Failed to compute checksum of %s: %s", path, err.Error())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FileExists checks if the given file exists
+func FileExists(path string) bool {
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	return false
+}
+
+// DirExists checks if the given directory exists
+func DirExists(dirPath string) bool {
+	if stat, err := os.Stat(dirPath); err == nil && stat.IsDir() {
+		return true
+	}
+	return false
+}
+
+// IsWritableDir checks if files can be created in the given directory by creating and removing a temporary file
+func IsWritableDir(path string) bool {
+	if !DirExists(path) {
+		return false
+	}
+	f, err := os.CreateTemp(path, ".gauge_write_check")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// EnsureDir creates the directory along with any missing parents using NewDirectoryPermissions.
+// An existing directory is not an error, an existing file at the path is.
+func EnsureDir(path string) error {
+	if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+		return fmt.Errorf("Failed to create directory %s. A file with the same name exists", path)
+	}
+	if err := os.MkdirAll(path, NewDirectoryPermissions); err != nil {
+		return fmt.Errorf("Failed to create directory %s. %s", path, err.Error())
+	}
+	return nil
+}
+
+// MirrorDir creates an exact copy of source dir to destination dir
+// Modified version of bradfitz's camlistore (https://github.com/bradfitz/camlistore/blob/master/make.go)
+func MirrorDir(src, dst string) ([]string, error) {
+	var filesAdded []string
+	err := filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		suffix, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("Failed to find Rel(%q, %q): %v", src, path, err)
+		}
+
+		err = MirrorFile(path, filepath.Join(dst, suffix))
+		filesAdded = append(filesAdded, suffix)
+		return err
+	})
+	return filesAdded, err
+}
+
+// MirrorDirWithStats creates an exact copy of source dir to destination dir like MirrorDir,
+// reporting the files which were copied separately from those skipped as unchanged
+func MirrorDirWithStats(src, dst string) (copied, skipped []string, err error) {
+	err = filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		suffix, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("Failed to find Rel(%q, %q): %v", src, path, err)
+		}
+
+		isCopied, err := mirrorFile(path, filepath.Join(dst, suffix))
+		if err != nil {
+			return err
+		}
+		if isCopied {
+			copied = append(copied, suffix)
+		} else {
+			skipped = append(skipped, suffix)
+		}
+		return nil
+	})
+	return copied, skipped, err
+}
+
+// MirrorDirParallel creates an exact copy of source dir to destination dir like MirrorDir,
+// copying the files across the given number of workers. Remaining copies are abandoned on the first error.
+func MirrorDirParallel(src, dst string, workers int) ([]string, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	var files []string
+	err := filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		suffix, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("Failed to find Rel(%q, %q): %v", src, path, err)
+		}
+		files = append(files, suffix)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-// ReadFileContents returns the contents of the file
-func ReadFileContents(file string) (string, error) {
-	if !FileExists(file) {
-		return "", fmt.Errorf("File %s doesn't exist.", file)
-	}
-	bytes, err := os.ReadFile(file)
-	if err != nil {
-		return "", fmt.Errorf("Failed to read the file %s.", file)
+	jobs := make(chan string)
+	failed := make(chan struct{})
+	var firstErr error
+	var once sync.Once
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for suffix := range jobs {
+				if err := MirrorFile(filepath.Join(src, suffix), filepath.Join(dst, suffix)); err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+				}
+			}
+		}()
 	}
-	return strings.TrimLeft(string(bytes), "\xef\xbb\xbf"), nil
-}
-
-// FileExists checks if the given file exists
-func FileExists(path string) bool {
-	if _, err := os.Stat(path); err == nil {
-		return true
+dispatch:
+	for _, suffix := range files {
+		select {
+		case jobs <- suffix:
+		case <-failed:
+			break dispatch
+		}
 	}
-	return false
-}
-
-// DirExists checks if the given directory exists
-func DirExists(dirPath string) bool {
-	if stat, err := os.Stat(dirPath); err == nil && stat.IsDir() {
-		return true
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
 	}
-	return false
+	return files, nil
 }
 
-// MirrorDir creates an exact copy of source dir to destination dir
-// Modified version of bradfitz's camlistore (https://github.com/bradfitz/camlistore/blob/master/make.go)
-func MirrorDir(src, dst string) ([]string, error) {
-	var filesAdded []string
-	err := filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+// CopyDir recursively copies the source directory to destination directory, including empty sub directories.
+// Symlinks are not followed, an error is returned if one is found.
+func CopyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if fi.IsDir() {
-			return nil
-		}
 		suffix, err := filepath.Rel(src, path)
 		if err != nil {
 			return fmt.Errorf("Failed to find Rel(%q, %q): %v", src, path, err)
 		}
-
-		err = MirrorFile(path, filepath.Join(dst, suffix))
-		filesAdded = append(filesAdded, suffix)
-		return err
+		target := filepath.Join(dst, suffix)
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("Cannot copy symlink %s", path)
+		}
+		if fi.IsDir() {
+			if tfi, err := os.Stat(target); err == nil && !tfi.IsDir() {
+				return fmt.Errorf("Cannot copy directory %s, %s is not a directory", path, target)
+			}
+			return os.MkdirAll(target, fi.Mode().Perm())
+		}
+		return CopyFile(path, target)
 	})
-	return filesAdded, err
 }
 
 // MirrorFile creates an exact copy of source file to destination file
 // Modified version of bradfitz's camlistore (https://github.com/bradfitz/camlistore/blob/master/make.go)
 func MirrorFile(src, dst string) error {
-	sfi, err := os.Stat(src)
+	_, err := mirrorFile(src, dst)
+	return err
+}
+
+// MirrorFileWithSymlinks creates an exact copy of source file to destination file like MirrorFile.
+// If src is a symlink, the link target is copied when followSymlinks is true, otherwise the symlink is recreated at dst.
+func MirrorFileWithSymlinks(src, dst string, followSymlinks bool) error {
+	sfi, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if followSymlinks || sfi.Mode()&os.ModeSymlink == 0 {
+		return MirrorFile(src, dst)
+	}
+	target, err := os.Readlink(src)
 	if err != nil {
 		return err
 	}
+	if err := EnsureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	return os.Symlink(target, dst)
+}
+
+// mirrorFile copies src to dst unless dst seems to be unmodified, reporting whether a copy was made
+func mirrorFile(src, dst string) (bool, error) {
+	sfi, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
 	if sfi.Mode()&os.ModeType != 0 {
-		log.Fatalf("mirrorFile can't deal with non-regular file %s", src)
+		return false, fmt.Errorf("mirrorFile can't deal with non-regular file %s", src)
 	}
 	dfi, err := os.Stat(dst)
 	if err == nil &&
@@ -405,21 +1876,20 @@ func MirrorFile(src, dst string) error {
 		dfi.Size() == sfi.Size() &&
 		dfi.ModTime().Unix() == sfi.ModTime().Unix() {
 		// Seems to not be modified.
-		return nil
+		return false, nil
 	}
 
-	dstDir := filepath.Dir(dst)
-	if err := os.MkdirAll(dstDir, 0755); err != nil {
-		return err
+	if err := EnsureDir(filepath.Dir(dst)); err != nil {
+		return false, err
 	}
 
 	df, err := os.Create(dst)
 	if err != nil {
-		return err
+		return false, err
 	}
 	sf, err := os.Open(src)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer sf.Close()
 
@@ -437,7 +1907,7 @@ func MirrorFile(src, dst string) error {
 	if err == nil {
 		err = os.Chtimes(dst, sfi.ModTime(), sfi.ModTime())
 	}
-	return err
+	return err == nil, err
 }
 
 func isExecMode(mode os.FileMode) bool {
@@ -455,23 +1925,40 @@ func GetUniqueID() int64 {
 	return uniqeID
 }
 
-// CopyFile creates a copy of source file to destination file
+// CopyFile creates a copy of source file to destination file, preserving its mode and modification time
 func CopyFile(src, dest string) error {
 	if !FileExists(src) {
-		return fmt.Errorf("%s doesn't exist", src)
+		return wrapError(ErrFileNotFound, "%s doesn't exist", src)
+	}
+
+	sfi, err := os.Stat(src)
+	if err != nil {
+		return err
 	}
 
-	b, err := os.ReadFile(src)
+	sf, err := os.Open(src)
 	if err != nil {
 		return err
 	}
+	defer sf.Close()
 
-	err = os.WriteFile(dest, b, NewFilePermissions)
+	df, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, sfi.Mode())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = io.CopyBuffer(df, sf, make([]byte, copyBufferSize))
+	cerr := df.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(dest, sfi.Mode())
+	}
+	if err == nil {
+		err = os.Chtimes(dest, sfi.ModTime(), sfi.ModTime())
+	}
+	return err
 }
 
 // Appends contents of source file to destination file.
@@ -502,11 +1989,17 @@ func AppendToFile(srcFile, destFile string) error {
 	return nil
 }
 
-// SetEnvVariable is a wrapper around os.SetEnv to set env variable
+// SetEnvVariable is a wrapper around os.SetEnv to set env variable.
+// Blank values are skipped and leave the variable untouched, use SetEnvVariableAllowEmpty to set them.
 func SetEnvVariable(key, value string) error {
 	if strings.TrimSpace(value) == "" {
 		return nil
 	}
+	return SetEnvVariableAllowEmpty(key, value)
+}
+
+// SetEnvVariableAllowEmpty is a wrapper around os.SetEnv to set env variable, including empty values
+func SetEnvVariableAllowEmpty(key, value string) error {
 	err := os.Setenv(key, value)
 	if err != nil {
 		return fmt.Errorf("Failed to set: %s = %s. %s", key, value, err.Error())
@@ -514,13 +2007,128 @@ func SetEnvVariable(key, value string) error {
 	return nil
 }
 
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadDotEnv parses the KEY=VALUE lines of a .env file. Blank lines and lines starting with # are ignored,
+// an "export " prefix is allowed and quotes surrounding a value are removed. Keys must be valid identifiers.
+func LoadDotEnv(path string) (map[string]string, error) {
+	lines, err := ReadFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if !found || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("Invalid line %d in %s: %s", i+1, path, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// ApplyEnvMap sets the env variables in the map using SetEnvVariable, so variables with empty values are skipped
+func ApplyEnvMap(m map[string]string) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := SetEnvVariable(key, m[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var envMutex = sync.Mutex{}
+
+// SnapshotEnv captures the current environment and returns a func which restores it,
+// removing variables added since the snapshot and resetting changed or removed ones.
+func SnapshotEnv() func() {
+	envMutex.Lock()
+	snapshot := environMap()
+	envMutex.Unlock()
+	return func() {
+		envMutex.Lock()
+		defer envMutex.Unlock()
+		for key := range environMap() {
+			if _, found := snapshot[key]; !found {
+				os.Unsetenv(key)
+			}
+		}
+		for key, value := range snapshot {
+			if current, found := os.LookupEnv(key); !found || current != value {
+				os.Setenv(key, value)
+			}
+		}
+	}
+}
+
+func environMap() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		// Windows has entries like =C:=C:\dir, so the separator is searched after the first character
+		if i := strings.Index(kv[1:], "="); i >= 0 {
+			env[kv[:i+1]] = kv[i+2:]
+		}
+	}
+	return env
+}
+
+// GetConfiguredPort returns the port set in the given env variable, or 0 if it is not set.
+// An error is returned if the value is not a number between 0 and 65535.
+func GetConfiguredPort(envName string) (int, error) {
+	value := strings.TrimSpace(os.Getenv(envName))
+	if value == "" {
+		return 0, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 0 || port > 65535 {
+		return 0, fmt.Errorf("Invalid port %s = %s. Port must be a number between 0 and 65535", envName, value)
+	}
+	return port, nil
+}
+
+// GetFreePort returns a port assigned by the OS which is free at the time of the call
+func GetFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("Failed to find a free port. %s", err.Error())
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
 // ExecuteCommand executes the given command in the working directory.
+// The command stays in the caller's process group, so on Unix the processes it spawns survive KillProcessGroup
+// and WaitWithTimeout. Use ExecuteCommandInProcessGroup for commands whose children must be killed with them.
 func ExecuteCommand(command []string, workingDir string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) (*exec.Cmd, error) {
 	cmd := prepareCommand(false, command, workingDir, outputStreamWriter, errorStreamWriter)
 	err := cmd.Start()
 	return cmd, err
 }
 
+// ExecuteCommandInProcessGroup executes the given command in the working directory in a new process group,
+// so that KillProcessGroup also kills the processes it spawns. The command then no longer receives the
+// signals sent to the terminal's foreground process group, like the SIGINT of Ctrl-C.
+func ExecuteCommandInProcessGroup(command []string, workingDir string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) (*exec.Cmd, error) {
+	cmd := prepareCommand(false, command, workingDir, outputStreamWriter, errorStreamWriter)
+	setProcessGroup(cmd)
+	err := cmd.Start()
+	return cmd, err
+}
+
 // ExecuteSystemCommand executes the given system command in the working directory.
 func ExecuteSystemCommand(command []string, workingDir string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) (*exec.Cmd, error) {
 	cmd := prepareCommand(true, command, workingDir, outputStreamWriter, errorStreamWriter)
@@ -536,6 +2144,203 @@ func ExecuteCommandWithEnv(command []string, workingDir string, outputStreamWrit
 	return cmd, err
 }
 
+// ExecuteCommandWithContext executes the given command in the working directory, killing it (and on Unix its process group)
+// when the context is done. Callers should cmd.Wait() and check ctx.Err() to distinguish a timeout from a normal failure.
+func ExecuteCommandWithContext(ctx context.Context, command []string, workingDir string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) (*exec.Cmd, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("Invalid executable command")
+	}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = workingDir
+	cmd.Stdout = outputStreamWriter
+	cmd.Stderr = errorStreamWriter
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return KillProcessGroup(cmd)
+	}
+	err := cmd.Start()
+	return cmd, err
+}
+
+// ExecuteCommandWithExtraEnv executes command with the current process environment overlaid with the given variables
+func ExecuteCommandWithExtraEnv(command []string, workingDir string, outputStreamWriter io.Writer, errorStreamWriter io.Writer, extra map[string]string) (*exec.Cmd, error) {
+	return ExecuteCommandWithEnv(command, workingDir, outputStreamWriter, errorStreamWriter, mergeEnv(os.Environ(), extra))
+}
+
+func mergeEnv(env []string, extra map[string]string) []string {
+	sameKey := func(a, b string) bool {
+		if isWindows() {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+	merged := []string{}
+	for _, kv := range env {
+		key := strings.SplitN(kv, "=", 2)[0]
+		overridden := false
+		for k := range extra {
+			if sameKey(key, k) {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, kv)
+		}
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		merged = append(merged, k+"="+extra[k])
+	}
+	return merged
+}
+
+// ExecuteCommandCollectOutput executes the given command in the working directory, waits for it to finish
+// and returns its stdout, stderr and exit code. A non zero exit code is not reported as an error.
+func ExecuteCommandCollectOutput(command []string, workingDir string) (string, string, int, error) {
+	var stdout, stderr bytes.Buffer
+	cmd, err := ExecuteCommand(command, workingDir, &stdout, &stderr)
+	if err != nil {
+		return "", "", -1, err
+	}
+	err = cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return stdout.String(), stderr.String(), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return stdout.String(), stderr.String(), -1, err
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode(), nil
+}
+
+// ExecuteCommandStreamingLines executes the given command in the working directory, calling onStdout and onStderr
+// with every line the command writes to stdout and stderr. All lines are delivered before cmd.Wait() returns.
+func ExecuteCommandStreamingLines(command []string, workingDir string, onStdout, onStderr func(line string)) (*exec.Cmd, error) {
+	return ExecuteCommand(command, workingDir, newLineReader(onStdout), newLineReader(onStderr))
+}
+
+// ExecuteCommandWithLineFilter executes the given command in the working directory, writing the lines of its
+// stdout and stderr for which keep returns true to out. Lines are written as they are read, each ending with a newline.
+func ExecuteCommandWithLineFilter(command []string, workingDir string, keep func(line string) bool, out io.Writer) (*exec.Cmd, error) {
+	var mutex sync.Mutex
+	filter := func(line string) {
+		if !keep(line) {
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		io.WriteString(out, line+"\n")
+	}
+	return ExecuteCommand(command, workingDir, newLineReader(filter), newLineReader(filter))
+}
+
+// lineReader is an io.Writer which splits the stream written to it into lines and calls onLine with each line.
+// A line split across writes is held back until it is complete. Lines longer than maxLineSize are delivered in
+// pieces of maxLineSize so the stream is always drained.
+// exec.Cmd copies the command's output to it through io.Copy, which uses ReadFrom and delivers the last line at EOF.
+type lineReader struct {
+	onLine  func(line string)
+	partial []byte
+}
+
+func newLineReader(onLine func(line string)) *lineReader {
+	return &lineReader{onLine: onLine}
+}
+
+func (l *lineReader) Write(b []byte) (int, error) {
+	l.partial = append(l.partial, b...)
+	start := 0
+	for {
+		i := bytes.IndexByte(l.partial[start:], '\n')
+		if i < 0 {
+			break
+		}
+		l.onLine(string(bytes.TrimSuffix(l.partial[start:start+i], []byte("\r"))))
+		start += i + 1
+	}
+	for len(l.partial)-start >= maxLineSize {
+		l.onLine(string(l.partial[start : start+maxLineSize]))
+		start += maxLineSize
+	}
+	l.partial = append(l.partial[:0], l.partial[start:]...)
+	return len(b), nil
+}
+
+func (l *lineReader) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	buf := make([]byte, copyBufferSize)
+	for {
+		read, err := r.Read(buf)
+		n += int64(read)
+		l.Write(buf[:read])
+		if err != nil {
+			l.flush()
+			if err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+}
+
+// flush delivers the last line of the stream if it did not end with a newline
+func (l *lineReader) flush() {
+	if len(l.partial) > 0 {
+		l.onLine(string(bytes.TrimSuffix(l.partial, []byte("\r"))))
+		l.partial = l.partial[:0]
+	}
+}
+
+// KillProcessGroup kills the given command along with the processes it spawned.
+// On Unix the spawned processes are only killed if the command was started in its own process group,
+// with ExecuteCommandInProcessGroup or ExecuteCommandWithContext. Only the command itself is killed when it was
+// started with ExecuteCommand; its children survive and keep any ports or files they hold open.
+func KillProcessGroup(cmd *exec.Cmd) error {
+	if cmd == nil || cmd.Process == nil {
+		return fmt.Errorf("Process not started")
+	}
+	return killProcessGroup(cmd)
+}
+
+// WaitWithTimeout waits for the started command to exit. If it does not exit within the timeout, the command is
+// killed with KillProcessGroup and an error wrapping ErrCommandTimeout is returned.
+// After the kill, WaitWithTimeout waits at most killWaitDelay for cmd.Wait to return. Processes spawned by a command
+// which was not started in its own process group survive the kill, and if they hold the command's output open
+// cmd.Wait only returns once they exit; WaitWithTimeout does not wait for them.
+func WaitWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+		killErr := KillProcessGroup(cmd)
+		select {
+		case err := <-done:
+			if killErr != nil {
+				// the command exited on its own before it could be killed
+				return err
+			}
+		case <-time.After(killWaitDelay):
+			if killErr != nil {
+				return wrapError(ErrCommandTimeout, "Command %s timed out after %s and could not be killed: %s", cmd.Path, timeout, killErr.Error())
+			}
+		}
+		return wrapError(ErrCommandTimeout, "Command %s timed out after %s", cmd.Path, timeout)
+	}
+}
+
 func prepareCommand(isSystemCommand bool, command []string, workingDir string, outputStreamWriter io.Writer, errorStreamWriter io.Writer) *exec.Cmd {
 	cmd := GetExecutableCommand(isSystemCommand, command...)
 	cmd.Dir = workingDir
@@ -545,11 +2350,17 @@ func prepareCommand(isSystemCommand bool, command []string, workingDir string, o
 }
 
 // GetExecutableCommand returns the path of the executable file
+// Commands given by name alone are resolved against PATH, paths are used as is.
 func GetExecutableCommand(isSystemCommand bool, command ...string) *exec.Cmd {
 	if len(command) == 0 {
 		panic(fmt.Errorf("Invalid executable command"))
 	}
 	cmd := &exec.Cmd{Path: command[0]}
+	if !isSystemCommand && !strings.ContainsAny(command[0], `/\`) {
+		if path, err := exec.LookPath(command[0]); err == nil {
+			cmd.Path = path
+		}
+	}
 	if len(command) > 1 {
 		if isSystemCommand {
 			cmd = exec.Command(command[0], command[1:]...)
@@ -559,26 +2370,188 @@ func GetExecutableCommand(isSystemCommand bool, command ...string) *exec.Cmd {
 		if isSystemCommand {
 			cmd = exec.Command(command[0])
 		}
-		cmd.Args = append([]string{command[0]})
+		cmd.Args = []string{command[0]}
 	}
 	return cmd
 }
 
+// ResolveExecutable returns the path of the named executable, looking it up in PATH if required
+func ResolveExecutable(name string) (string, error) {
+	if isWindows() && filepath.Ext(name) == "" {
+		name += ".exe"
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("Executable %s not found in PATH: %s", name, err.Error())
+	}
+	return path, nil
+}
+
+// OpenInDefaultApp opens the given file or url with the default application of the OS
+func OpenInDefaultApp(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		if strings.Contains(path, `"`) {
+			return fmt.Errorf("Cannot open %s: path contains a double quote", path)
+		}
+		cmd = exec.Command("cmd")
+		setCmdLine(cmd, fmt.Sprintf(`cmd /c start "" "%s"`, path))
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		opener, err := exec.LookPath("xdg-open")
+		if err != nil {
+			return fmt.Errorf("Cannot open %s: xdg-open not found in PATH", path)
+		}
+		cmd = exec.Command(opener, path)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Failed to open %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+// AcquireLock takes an exclusive OS level lock on the lock file, blocking until any other holder releases it.
+// A relative lockPath is resolved under the gauge home directory. The lock is released by calling the returned func,
+// and by the OS if the process exits without doing so.
+func AcquireLock(lockPath string) (func() error, error) {
+	if !filepath.IsAbs(lockPath) {
+		gaugeHome, err := GetGaugeHomeDirectory()
+		if err != nil {
+			return nil, err
+		}
+		lockPath = filepath.Join(gaugeHome, lockPath)
+	}
+	if err := EnsureDir(filepath.Dir(lockPath)); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, NewFilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create lock file %s: %s", lockPath, err.Error())
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("Failed to lock %s: %s", lockPath, err.Error())
+	}
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			err = unlockFile(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		})
+		return err
+	}, nil
+}
+
 // GetTempDir returns the system temp directory
 func GetTempDir() string {
 	tempGaugeDir := filepath.Join(os.TempDir(), "gauge_temp")
-	tempGaugeDir += strconv.FormatInt(time.Now().UnixNano(), 10)
+	tempGaugeDir += strconv.FormatInt(time.Now().UnixNano(), 10) + "_" + strconv.FormatInt(GetUniqueID(), 10)
 	if !exists(tempGaugeDir) {
 		os.MkdirAll(tempGaugeDir, NewDirectoryPermissions)
 	}
+	RegisterTempDirForCleanup(tempGaugeDir)
 	return tempGaugeDir
 }
 
+var processTempDir string
+var processTempDirOnce sync.Once
+
+// GetProcessTempDir returns a temp directory which is shared for the lifetime of the process.
+// The directory is registered for cleanup and recreated if it has been removed.
+func GetProcessTempDir() string {
+	processTempDirOnce.Do(func() {
+		processTempDir = GetTempDir()
+	})
+	if !exists(processTempDir) {
+		os.MkdirAll(processTempDir, NewDirectoryPermissions)
+		RegisterTempDirForCleanup(processTempDir)
+	}
+	return processTempDir
+}
+
+var tempDirs []string
+var tempDirsMutex = sync.Mutex{}
+
+// RegisterTempDirForCleanup registers the given directory to be removed by CleanupTempDirs
+func RegisterTempDirForCleanup(path string) {
+	tempDirsMutex.Lock()
+	defer tempDirsMutex.Unlock()
+	for _, dir := range tempDirs {
+		if dir == path {
+			return
+		}
+	}
+	tempDirs = append(tempDirs, path)
+}
+
+// CleanupTempDirs removes the directories created by GetTempDir or registered with RegisterTempDirForCleanup.
+// Callers should invoke it before the process exits. The first error encountered is returned.
+func CleanupTempDirs() error {
+	tempDirsMutex.Lock()
+	defer tempDirsMutex.Unlock()
+	var firstErr error
+	var remaining []string
+	for _, dir := range tempDirs {
+		if err := os.RemoveAll(dir); err != nil {
+			remaining = append(remaining, dir)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	tempDirs = remaining
+	return firstErr
+}
+
 // Remove removes all the files and directories recursively for the given path
 func Remove(path string) error {
 	return os.RemoveAll(path)
 }
 
+// RemoveContents removes all the files and directories inside the given directory, leaving the directory itself in place.
+// The first error encountered is returned.
+func RemoveContents(dir string) error {
+	if !DirExists(dir) {
+		return fmt.Errorf("Directory %s does not exist", dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DirSize returns the total size in bytes of all the regular files under the given path. Symlinks are not followed.
+func DirSize(path string) (int64, error) {
+	var size int64
+	if _, err := os.Lstat(path); err != nil {
+		return 0, wrapError(err, "Failed to find size of %s: %s", path, err.Error())
+	}
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, wrapError(err, "Failed to find size of %s: %s", path, err.Error())
+	}
+	return size, nil
+}
+
 func exists(path string) bool {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return false
@@ -588,25 +2561,52 @@ func exists(path string) bool {
 
 // UnzipArchive extract the zip file to destination directory
 func UnzipArchive(zipFile string, dest string) (string, error) {
+	if _, err := UnzipArchiveVerbose(zipFile, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// UnzipArchiveVerbose extract the zip file to destination directory and returns the paths, relative to dest, of the files written.
+// On failure the files written so far are returned along with the error.
+func UnzipArchiveVerbose(zipFile string, dest string) ([]string, error) {
+	return unzipArchive(zipFile, dest, nil)
+}
+
+// UnzipArchiveWithProgress extract the zip file to destination directory like UnzipArchive, calling onEntry after
+// each entry is extracted with the entry name, its 1-based index and the total number of entries in the zip file.
+func UnzipArchiveWithProgress(zipFile, dest string, onEntry func(name string, index, total int)) (string, error) {
+	if _, err := unzipArchive(zipFile, dest, onEntry); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+func unzipArchive(zipFile string, dest string, onEntry func(name string, index, total int)) ([]string, error) {
 	if !FileExists(zipFile) {
-		return "", fmt.Errorf("ZipFile %s does not exist", zipFile)
+		return nil, fmt.Errorf("ZipFile %s does not exist", zipFile)
 	}
 
 	r, err := zip.OpenReader(zipFile)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer r.Close()
 
-	for _, f := range r.File {
+	var extracted []string
+	for i, f := range r.File {
+		path, err := SafeJoin(dest, f.Name)
+		if err != nil {
+			return extracted, fmt.Errorf("Illegal file path in zip %s: %s", zipFile, f.Name)
+		}
+		rel, _ := relInDir(dest, path)
 		rc, err := f.Open()
 		if err != nil {
-			return "", err
+			return extracted, err
 		}
 		error := func() error {
 			defer rc.Close()
 
-			path := filepath.Join(dest, f.Name)
 			os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions)
 			if f.FileInfo().IsDir() {
 				os.MkdirAll(path, f.Mode())
@@ -618,6 +2618,7 @@ func UnzipArchive(zipFile string, dest string) (string, error) {
 				}
 				defer f.Close()
 
+				extracted = append(extracted, rel)
 				_, err = io.Copy(f, rc)
 				if err != nil {
 					return err
@@ -626,16 +2627,250 @@ func UnzipArchive(zipFile string, dest string) (string, error) {
 			return nil
 		}()
 		if error != nil {
-			return "", error
+			return extracted, error
 
 		}
+		if onEntry != nil {
+			onEntry(f.Name, i+1, len(r.File))
+		}
+	}
+
+	return extracted, nil
+}
+
+// ExtractArchive extracts the .zip, .tar.gz or .tgz archive to destination directory
+func ExtractArchive(archivePath, dest string) (string, error) {
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return UnzipArchive(archivePath, dest)
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		return untarGzArchive(archivePath, dest)
+	}
+	return "", fmt.Errorf("Unsupported archive format: %s", archivePath)
+}
+
+func untarGzArchive(archivePath, dest string) (string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read %s: %s", archivePath, err.Error())
 	}
+	defer gz.Close()
 
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("Failed to read %s: %s", archivePath, err.Error())
+		}
+		path, err := SafeJoin(dest, header.Name)
+		if err != nil {
+			return "", fmt.Errorf("Illegal file path in archive %s: %s", archivePath, header.Name)
+		}
+		mode := header.FileInfo().Mode()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode.Perm()); err != nil {
+				return "", err
+			}
+		case tar.TypeReg:
+			if err := EnsureDir(filepath.Dir(path)); err != nil {
+				return "", err
+			}
+			err := func() error {
+				out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+				_, err = io.Copy(out, tr)
+				return err
+			}()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
 	return dest, nil
 }
 
-// SaveFile saves contents at the given filepath
+// GzipFile compresses the source file into the destination file, preserving the file mode
+func GzipFile(src, dst string) error {
+	return transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		gz := gzip.NewWriter(w)
+		if _, err := io.Copy(gz, r); err != nil {
+			gz.Close()
+			return err
+		}
+		return gz.Close()
+	})
+}
+
+// GunzipFile decompresses the gzipped source file into the destination file, preserving the file mode
+func GunzipFile(src, dst string) error {
+	return transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		_, err = io.Copy(w, gz)
+		return err
+	})
+}
+
+func transformFile(src, dst string, transform func(w io.Writer, r io.Reader) error) error {
+	sfi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	sf, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	df, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, sfi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	err = transform(df, sf)
+	cerr := df.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(dst, sfi.Mode().Perm())
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to write %s from %s: %s", dst, src, err.Error())
+	}
+	return nil
+}
+
+// SafeJoin joins the untrusted path to base, returning an error if the result would lie outside base.
+// Absolute untrusted paths are rejected.
+func SafeJoin(base, untrusted string) (string, error) {
+	if filepath.IsAbs(untrusted) || filepath.VolumeName(untrusted) != "" || strings.HasPrefix(untrusted, "/") || strings.HasPrefix(untrusted, `\`) {
+		return "", fmt.Errorf("Path %s is not relative to %s", untrusted, base)
+	}
+	path := filepath.Join(base, untrusted)
+	if _, ok := relInDir(filepath.Clean(base), path); !ok {
+		return "", fmt.Errorf("Path %s escapes %s", untrusted, base)
+	}
+	return path, nil
+}
+
+// relInDir returns the path relative to dir, and whether path lies within dir
+func relInDir(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// ZipDir creates a zip file at targetZip with the contents of sourceDir, including empty directories.
+// Entries are named with forward slashes relative to sourceDir and keep their file modes.
+func ZipDir(sourceDir, targetZip string) (string, error) {
+	zipFile, err := os.Create(targetZip)
+	if err != nil {
+		return "", err
+	}
+	zipInfo, err := zipFile.Stat()
+	if err != nil {
+		zipFile.Close()
+		os.Remove(targetZip)
+		return "", err
+	}
+
+	w := zip.NewWriter(zipFile)
+	err = filepath.Walk(sourceDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if os.SameFile(fi, zipInfo) {
+			// the zip is being written inside sourceDir
+			return nil
+		}
+		rel, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return fmt.Errorf("Failed to find Rel(%q, %q): %v", sourceDir, path, err)
+		}
+		if rel == "." {
+			return nil
+		}
+		header, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if fi.IsDir() {
+			header.Name += "/"
+			_, err = w.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+		writer, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(writer, f)
+		return err
+	})
+	if err != nil {
+		w.Close()
+	} else {
+		err = w.Close()
+	}
+	if closeErr := zipFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(targetZip)
+		return "", err
+	}
+	return targetZip, nil
+}
+
+// SaveFile saves contents at the given filepath.
+// An existing file keeps its permissions, new files are created with NewFilePermissions.
 func SaveFile(filePath, contents string, takeBackup bool) error {
+	_, err := SaveFileReturningBackup(filePath, contents, takeBackup)
+	return err
+}
+
+// SaveFileReturningBackup saves contents at the given filepath like SaveFile and returns the path of the backup file.
+// The backup is created in the system temp directory, the returned path is empty if takeBackup is false.
+func SaveFileReturningBackup(filePath, contents string, takeBackup bool) (string, error) {
+	mode := os.FileMode(NewFilePermissions)
+	if fi, err := os.Stat(filePath); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	return saveFile(filePath, contents, takeBackup, mode)
+}
+
+// SaveFileWithMode saves contents at the given filepath and sets the file permissions to mode
+func SaveFileWithMode(filePath, contents string, takeBackup bool, mode os.FileMode) error {
+	_, err := saveFile(filePath, contents, takeBackup, mode)
+	return err
+}
+
+func saveFile(filePath, contents string, takeBackup bool, mode os.FileMode) (string, error) {
 	backupFile := ""
 	if takeBackup {
 		tmpDir := os.TempDir()
@@ -643,15 +2878,18 @@ func SaveFile(filePath, contents string, takeBackup bool) error {
 		backupFile = filepath.Join(tmpDir, fileName)
 		err := CopyFile(filePath, backupFile)
 		if err != nil {
-			return fmt.Errorf("Failed to make backup for '%s': %s", filePath, err.Error())
+			return "", fmt.Errorf("Failed to make backup for '%s': %s", filePath, err.Error())
 		}
 	}
-	err := os.WriteFile(filePath, []byte(contents), NewFilePermissions)
+	err := os.WriteFile(filePath, []byte(contents), mode)
 	if err != nil {
-		return fmt.Errorf("Failed to write to '%s': %s", filePath, err.Error())
+		return backupFile, fmt.Errorf("Failed to write to '%s': %s", filePath, err.Error())
+	}
+	if err = os.Chmod(filePath, mode); err != nil {
+		return backupFile, fmt.Errorf("Failed to set permissions of '%s': %s", filePath, err.Error())
 	}
 
-	return nil
+	return backupFile, nil
 }
 
 func getUserHomeFromEnv() string {
@@ -669,6 +2907,11 @@ func isWindows() bool {
 	return runtime.GOOS == "windows"
 }
 
+// isCaseInsensitiveOS checks if the default file system of the OS ignores case in file names
+func isCaseInsensitiveOS() bool {
+	return isWindows() || runtime.GOOS == "darwin"
+}
+
 // TrimTrailingSpace trims the trailing spaces in the given string
 func TrimTrailingSpace(str string) string {
 	var r = regexp.MustCompile(`[ \t]+$`)
@@ -676,21 +2919,255 @@ func TrimTrailingSpace(str string) string {
 }
 
 func (property *Property) String() string {
-	return fmt.Sprintf("#%s\n%s = %s", property.Comment, property.Name, property.DefaultValue)
+	if property.Comment == "" {
+		return fmt.Sprintf("%s = %s", property.Name, property.DefaultValue)
+	}
+	comment := "# " + strings.Join(strings.Split(property.Comment, "\n"), "\n# ")
+	return fmt.Sprintf("%s\n%s = %s", comment, property.Name, property.DefaultValue)
+}
+
+var httpClient = newHTTPClient()
+
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.ResponseHeaderTimeout = defaultURLTimeout
+	return &http.Client{Transport: transport}
+}
+
+// SetHTTPClient sets the client used for all network calls, e.g. to use a custom transport or CA.
+// Passing nil restores the default client, which honors HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// and gives up on servers which do not respond within defaultURLTimeout.
+func SetHTTPClient(c *http.Client) {
+	if c == nil {
+		c = newHTTPClient()
+	}
+	httpClient = c
 }
 
 // UrlExists checks if the given url exists
 func UrlExists(url string) (bool, error) {
-	resp, err := http.Head(url)
+	return UrlExistsWithTimeout(url, defaultURLTimeout)
+}
+
+// UrlExistsWithTimeout checks if the given url exists, giving up after the given timeout.
+// Redirects are followed. A 404 reports the url as missing, server errors are returned as errors.
+func UrlExistsWithTimeout(url string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("Failed to resolve host")
+		return false, wrapError(err, "Failed to check %s: %s", url, err.Error())
 	}
-	if resp.StatusCode == 200 {
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 400:
 		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 500:
+		return false, fmt.Errorf("Server error while checking %s, %d-%s", url, resp.StatusCode, resp.Status)
 	}
 	return false, fmt.Errorf("Could not get %s, %d-%s", url, resp.StatusCode, resp.Status)
 }
 
+// DownloadAndVerify downloads the given url into targetDir, naming the file after the last element of the url path,
+// and checks that its SHA256 digest matches expectedSHA256. The download is only moved into place if the digests match,
+// so a mismatch leaves any existing file in targetDir untouched. Returns the path of the downloaded file.
+func DownloadAndVerify(fileURL, targetDir, expectedSHA256 string) (string, error) {
+	destPath, err := downloadPath(fileURL, targetDir)
+	if err != nil {
+		return "", err
+	}
+	err = downloadToFile(context.Background(), fileURL, destPath, nil, func(downloaded string) error {
+		actual, err := FileChecksum(downloaded)
+		if err != nil {
+			return fmt.Errorf("Failed to compute checksum of %s: %s", fileURL, err.Error())
+		}
+		if expected := strings.ToLower(expectedSHA256); actual != expected {
+			return fmt.Errorf("Checksum mismatch for %s: expected %s, got %s", fileURL, expected, actual)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+// Download downloads the given url into targetDir, naming the file after the last element of the url path.
+// Returns the path of the downloaded file.
+func Download(url, targetDir string) (string, error) {
+	return DownloadWithContext(context.Background(), url, targetDir)
+}
+
+// DownloadToTempDir downloads the given url into a new temp directory, see Download.
+func DownloadToTempDir(url string) (string, error) {
+	return Download(url, GetTempDir())
+}
+
+// DownloadWithContext downloads the given url into targetDir like Download, aborting the download when ctx is done.
+// A cancelled download never leaves a partial file in targetDir.
+func DownloadWithContext(ctx context.Context, url, targetDir string) (string, error) {
+	destPath, err := downloadPath(url, targetDir)
+	if err != nil {
+		return "", err
+	}
+	if err := downloadToFile(ctx, url, destPath, nil, nil); err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+// DownloadToFile downloads the given url to destPath, creating its parent directories if needed.
+// The download is written to a temporary file next to destPath which is renamed once complete,
+// so a failed download never leaves a partial file at destPath.
+func DownloadToFile(url, destPath string) error {
+	return downloadToFile(context.Background(), url, destPath, nil, nil)
+}
+
+// DownloadWithRetry downloads the given url into targetDir like Download, making up to maxAttempts attempts.
+// Network errors and 5xx responses are retried after an exponential backoff starting at baseDelay, with jitter.
+// Other responses, like 404, are not retried. Every attempt starts from a clean file, and the returned error
+// reports how many attempts were made.
+func DownloadWithRetry(url, targetDir string, maxAttempts int, baseDelay time.Duration) (string, error) {
+	destPath, err := downloadPath(url, targetDir)
+	if err != nil {
+		return "", err
+	}
+	delay := baseDelay
+	for attempt := 1; ; attempt++ {
+		err = downloadToFile(context.Background(), url, destPath, nil, nil)
+		if err == nil {
+			return destPath, nil
+		}
+		if attempt >= maxAttempts || !isRetryableDownloadError(err) {
+			return "", wrapError(err, "Failed to download %s after %d attempt(s): %s", url, attempt, err.Error())
+		}
+		time.Sleep(delay/2 + rand.N(delay/2+1))
+		delay *= 2
+	}
+}
+
+// isRetryableDownloadError returns true for network errors and 5xx responses
+func isRetryableDownloadError(err error) bool {
+	var statusErr *downloadStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// downloadStatusError is returned when the server responds to a download with a non 2xx status
+type downloadStatusError struct {
+	url        string
+	statusCode int
+	status     string
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("Failed to download %s, %d-%s", e.url, e.statusCode, e.status)
+}
+
+// DownloadWithProgress downloads the given url to targetFile like DownloadToFile, calling onProgress with the bytes
+// written so far and the total size from the Content-Length header, or -1 if it is unknown.
+// onProgress is called at most every progressInterval, and a final time with the complete byte count on success.
+func DownloadWithProgress(url, targetFile string, onProgress func(bytesWritten, totalBytes int64)) error {
+	return downloadToFile(context.Background(), url, targetFile, onProgress, nil)
+}
+
+// downloadPath returns the path in targetDir named after the last element of the url path
+func downloadPath(fileURL, targetDir string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid url %s: %s", fileURL, err.Error())
+	}
+	name := path.Base(u.Path)
+	if !isPathElement(name) {
+		return "", fmt.Errorf("Invalid url %s: no file name in path", fileURL)
+	}
+	return filepath.Join(targetDir, name), nil
+}
+
+// downloadToFile downloads url to a temporary file next to destPath and renames it to destPath.
+// If onProgress is not nil it is called as the download progresses, see DownloadWithProgress.
+// If verify is not nil it is called with the temporary file once the download completes, and the file is only
+// renamed to destPath if it returns nil.
+func downloadToFile(ctx context.Context, url, destPath string, onProgress func(bytesWritten, totalBytes int64), verify func(downloaded string) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("Invalid url %s: %s", url, err.Error())
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return wrapError(err, "Failed to download %s: %s", url, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &downloadStatusError{url: url, statusCode: resp.StatusCode, status: resp.Status}
+	}
+	destDir := filepath.Dir(destPath)
+	if err := EnsureDir(destDir); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(destDir, "."+filepath.Base(destPath)+".tmp")
+	if err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", destPath, err.Error())
+	}
+	defer os.Remove(tmp.Name())
+	var w io.Writer = tmp
+	progress := &progressWriter{w: tmp, total: resp.ContentLength, onProgress: onProgress}
+	if onProgress != nil {
+		w = progress
+	}
+	if _, err := io.CopyBuffer(w, resp.Body, make([]byte, copyBufferSize)); err != nil {
+		tmp.Close()
+		return wrapError(err, "Failed to download %s: %s", url, err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", destPath, err.Error())
+	}
+	if verify != nil {
+		if err := verify(tmp.Name()); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(tmp.Name(), NewFilePermissions); err != nil {
+		return fmt.Errorf("Failed to set permissions of '%s': %s", destPath, err.Error())
+	}
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		return fmt.Errorf("Failed to write to '%s': %s", destPath, err.Error())
+	}
+	if onProgress != nil {
+		onProgress(progress.written, progress.total)
+	}
+	return nil
+}
+
+// progressWriter is an io.Writer which counts the bytes written to w and reports them at most every progressInterval
+type progressWriter struct {
+	w          io.Writer
+	written    int64
+	total      int64
+	onProgress func(bytesWritten, totalBytes int64)
+	reported   time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if now := time.Now(); now.Sub(p.reported) >= progressInterval {
+		p.reported = now
+		p.onProgress(p.written, p.total)
+	}
+	return n, err
+}
+
 // GetPluginProperties returns the properties of the given plugin.
 func GetPluginProperties(jsonPropertiesFile string) (map[string]interface{}, error) {
 	pluginPropertiesJSON, err := os.ReadFile(jsonPropertiesFile)
@@ -701,14 +3178,113 @@ func GetPluginProperties(jsonPropertiesFile string) (map[string]interface{}, err
 	if err = json.Unmarshal([]byte(pluginPropertiesJSON), &pluginJSON); err != nil {
 		return nil, fmt.Errorf("Could not read %s: %s\n", filepath.Base(jsonPropertiesFile), err)
 	}
-	return pluginJSON.(map[string]interface{}), nil
+	pluginProperties, ok := pluginJSON.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Could not read %s: expected a JSON object\n", filepath.Base(jsonPropertiesFile))
+	}
+	return pluginProperties, nil
+}
+
+// ValidatePluginProperties checks that the plugin properties have the required fields with the expected types
+func ValidatePluginProperties(props map[string]interface{}) error {
+	if props == nil {
+		return fmt.Errorf("Invalid plugin properties: no properties found")
+	}
+	for _, key := range []string{"id", "version"} {
+		value, ok := props[key]
+		if !ok {
+			return fmt.Errorf("Invalid plugin properties: %s field missing", key)
+		}
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("Invalid plugin properties: %s is not a string", key)
+		}
+	}
+	return nil
+}
+
+// IsPluginCompatible checks if the given gauge version satisfies the gaugeVersion constraint in the plugin properties.
+// The constraint is either a minimum version, or space separated comparisons like ">=1.0.0 <2.0.0" or ">= 1.0.0 < 2.0.0".
+// Plugins without a gaugeVersion constraint are compatible with every version.
+func IsPluginCompatible(pluginProps map[string]interface{}, gaugeVersion string) (bool, error) {
+	value, ok := pluginProps["gaugeVersion"]
+	if !ok {
+		return true, nil
+	}
+	constraint, ok := value.(string)
+	if !ok {
+		return false, fmt.Errorf("Invalid plugin properties: gaugeVersion is not a string")
+	}
+	current, err := parseVersion(gaugeVersion)
+	if err != nil {
+		return false, err
+	}
+	fields := strings.Fields(constraint)
+	for i := 0; i < len(fields); i++ {
+		clause := fields[i]
+		if strings.TrimLeft(clause, "<>=") == "" && i+1 < len(fields) {
+			// the operator is separated from its version, as in ">= 1.0.0"
+			i++
+			clause += fields[i]
+		}
+		versionString := strings.TrimLeft(clause, "<>=")
+		operator := clause[:len(clause)-len(versionString)]
+		required, err := parseVersion(versionString)
+		if err != nil {
+			return false, fmt.Errorf("Invalid gaugeVersion constraint %s. %s", constraint, err.Error())
+		}
+		c := current.compare(required)
+		var satisfied bool
+		switch operator {
+		case "", ">=":
+			satisfied = c >= 0
+		case ">":
+			satisfied = c > 0
+		case "<=":
+			satisfied = c <= 0
+		case "<":
+			satisfied = c < 0
+		case "=", "==":
+			satisfied = c == 0
+		default:
+			return false, fmt.Errorf("Invalid gaugeVersion constraint %s", constraint)
+		}
+		if !satisfied {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
-// GetGaugePluginVersion returns the latest version installed of the given plugin
+// GetGaugePluginVersion returns the latest version installed of the given plugin.
+// <pluginName>.json is read from the working directory if present, otherwise from the latest installed version of the plugin.
 func GetGaugePluginVersion(pluginName string) (string, error) {
-	pluginProperties, err := GetPluginProperties(fmt.Sprintf("%s.json", pluginName))
+	if FileExists(fmt.Sprintf("%s.json", pluginName)) {
+		return GetGaugePluginVersionFromDir("", pluginName)
+	}
+	pluginsDir, err := GetPluginsInstallDir(pluginName)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get gauge %s properties file. %s", pluginName, err)
+	}
+	latest, err := GetLatestInstalledPluginVersion(pluginName)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get gauge %s properties file. %s", pluginName, err)
+	}
+	return GetGaugePluginVersionFromDir(filepath.Join(pluginsDir, pluginName, latest), pluginName)
+}
+
+// GetGaugePluginVersionFromDir returns the version of the given plugin from <pluginDir>/<pluginName>.json
+func GetGaugePluginVersionFromDir(pluginDir, pluginName string) (string, error) {
+	pluginProperties, err := GetPluginProperties(filepath.Join(pluginDir, fmt.Sprintf("%s.json", pluginName)))
 	if err != nil {
 		return "", fmt.Errorf("Failed to get gauge %s properties file. %s", pluginName, err)
 	}
-	return pluginProperties["version"].(string), nil
+	value, ok := pluginProperties["version"]
+	if !ok {
+		return "", fmt.Errorf("Failed to get gauge %s version. version field missing", pluginName)
+	}
+	version, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Failed to get gauge %s version. version is not a string", pluginName)
+	}
+	return version, nil
 }
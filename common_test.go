@@ -7,13 +7,29 @@
 package common
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	properties "github.com/dmotylev/goproperties"
 	. "gopkg.in/check.v1"
 )
 
@@ -21,6 +37,30 @@ const dummyProject = "dummy_proj"
 
 func Test(t *testing.T) { TestingT(t) }
 
+const helperProcessEnv = "GAUGE_COMMON_HELPER_PROCESS"
+
+// The test binary acts as a helper process for command execution tests when helperProcessEnv is set.
+// It writes its first two arguments to stdout and stderr and exits with the third,
+// after sleeping for the optional fourth argument.
+func init() {
+	if os.Getenv(helperProcessEnv) != "1" {
+		return
+	}
+	args := os.Args[1:]
+	fmt.Fprint(os.Stdout, args[0])
+	fmt.Fprint(os.Stderr, args[1])
+	if len(args) > 3 {
+		d, _ := time.ParseDuration(args[3])
+		time.Sleep(d)
+	}
+	code, _ := strconv.Atoi(args[2])
+	os.Exit(code)
+}
+
+func helperCommand(args ...string) []string {
+	return append([]string{os.Args[0]}, args...)
+}
+
 type MySuite struct {
 	testDir string
 }
@@ -101,6 +141,83 @@ func (s *MySuite) TestGetProjectRootFromNestedDir(c *C) {
 	c.Assert(root, Equals, expectedRoot)
 }
 
+func (s *MySuite) TestGetProjectRootFromEnv(c *C) {
+	expectedRoot := getAbsPath(dummyProject)
+	os.Setenv(GaugeProjectRootEnv, expectedRoot)
+	defer os.Unsetenv(GaugeProjectRootEnv)
+	os.Chdir(os.TempDir())
+
+	root, err := GetProjectRoot()
+
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+}
+
+func (s *MySuite) TestGetProjectRootWithInvalidEnv(c *C) {
+	expectedRoot := getAbsPath(dummyProject)
+	os.Setenv(GaugeProjectRootEnv, filepath.Join(expectedRoot, "specs"))
+	defer os.Unsetenv(GaugeProjectRootEnv)
+	os.Chdir(dummyProject)
+
+	root, err := GetProjectRoot()
+
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+}
+
+func (s *MySuite) TestGetProjectRootWithoutEnv(c *C) {
+	os.Unsetenv(GaugeProjectRootEnv)
+	expectedRoot := getAbsPath(dummyProject)
+	os.Chdir(filepath.Join(dummyProject, "specs"))
+
+	root, err := GetProjectRoot()
+
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+}
+
+func (s *MySuite) TestGetProjectRootFromDir(c *C) {
+	expectedRoot := getAbsPath(dummyProject)
+
+	root, err := GetProjectRootFromDir(filepath.Join(dummyProject, "specs", "nested"))
+
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+}
+
+func (s *MySuite) TestFindRootWithMarker(c *C) {
+	expectedRoot := getAbsPath(dummyProject)
+
+	root, err := FindRootWithMarker(filepath.Join(dummyProject, "specs", "nested"), "gauge.json", ".git")
+
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+}
+
+func (s *MySuite) TestFindRootWithMissingMarker(c *C) {
+	_, err := FindRootWithMarker(os.TempDir(), "gauge.json")
+
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "Failed to find Gauge project directory. Missing gauge.json file.")
+}
+
+func (s *MySuite) TestGetProjectRootCached(c *C) {
+	defer ClearProjectRootCache()
+	project := filepath.Join(c.MkDir(), "project")
+	createDummyProject(project)
+	nested := filepath.Join(project, "specs", "nested")
+	expectedRoot, _ := filepath.Abs(project)
+
+	root, err := GetProjectRootCached(nested)
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+
+	os.Remove(filepath.Join(project, ManifestFile))
+
+	_, err = GetProjectRootCached(nested)
+	c.Assert(err, NotNil)
+}
+
 func (s *MySuite) TestGetProjectFailing(c *C) {
 
 	_, err := GetProjectRoot()
@@ -108,6 +225,119 @@ func (s *MySuite) TestGetProjectFailing(c *C) {
 	c.Assert(err.Error(), Equals, "Failed to find Gauge project directory. Missing manifest.json file.")
 }
 
+func (s *MySuite) TestReadManifest(c *C) {
+	projectRoot := c.MkDir()
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{"Language": "java", "Plugins": ["html-report"], "EnvironmentVariables": {"a": "b"}}`), NewFilePermissions)
+
+	manifest, err := ReadManifest(projectRoot)
+
+	c.Assert(err, IsNil)
+	c.Assert(manifest.Language, Equals, "java")
+	c.Assert(manifest.Plugins, DeepEquals, []string{"html-report"})
+	c.Assert(string(manifest.Extra["EnvironmentVariables"]), Equals, `{"a": "b"}`)
+}
+
+func (s *MySuite) TestReadMissingOrMalformedManifest(c *C) {
+	projectRoot := c.MkDir()
+
+	_, err := ReadManifest(projectRoot)
+	c.Assert(err, NotNil)
+
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{"Language": `), NewFilePermissions)
+
+	_, err = ReadManifest(projectRoot)
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestWriteManifestPreservesUnknownFields(c *C) {
+	projectRoot := c.MkDir()
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{"Language": "java", "Plugins": [], "Custom": 1}`), NewFilePermissions)
+	manifest, _ := ReadManifest(projectRoot)
+	manifest.Plugins = append(manifest.Plugins, "html-report")
+
+	err := WriteManifest(projectRoot, manifest)
+
+	c.Assert(err, IsNil)
+	contents, _ := ReadFileContents(filepath.Join(projectRoot, ManifestFile))
+	c.Assert(contents, Equals, "{\n  \"Custom\": 1,\n  \"Language\": \"java\",\n  \"Plugins\": [\n    \"html-report\"\n  ]\n}\n")
+}
+
+func (s *MySuite) TestAddPluginToManifest(c *C) {
+	projectRoot := c.MkDir()
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{"Language": "java", "Plugins": ["html-report"], "Custom": 1}`), NewFilePermissions)
+
+	c.Assert(AddPluginToManifest(projectRoot, "xml-report"), IsNil)
+	c.Assert(AddPluginToManifest(projectRoot, "html-report"), IsNil)
+
+	contents, _ := ReadFileContents(filepath.Join(projectRoot, ManifestFile))
+	c.Assert(contents, Equals, "{\n  \"Custom\": 1,\n  \"Language\": \"java\",\n  \"Plugins\": [\n    \"html-report\",\n    \"xml-report\"\n  ]\n}\n")
+}
+
+func (s *MySuite) TestRemovePluginFromManifest(c *C) {
+	projectRoot := c.MkDir()
+	manifestFile := filepath.Join(projectRoot, ManifestFile)
+	os.WriteFile(manifestFile, []byte(`{"Language": "java", "Plugins": ["html-report", "xml-report"], "Custom": 1}`), NewFilePermissions)
+
+	c.Assert(RemovePluginFromManifest(projectRoot, "html-report"), IsNil)
+	manifest, _ := ReadManifest(projectRoot)
+	c.Assert(manifest.Plugins, DeepEquals, []string{"xml-report"})
+	c.Assert(string(manifest.Extra["Custom"]), Equals, "1")
+
+	before, _ := ReadFileContents(manifestFile)
+	c.Assert(RemovePluginFromManifest(projectRoot, "html-report"), IsNil)
+	after, _ := ReadFileContents(manifestFile)
+	c.Assert(after, Equals, before)
+}
+
+func (s *MySuite) TestAddPluginToMissingManifest(c *C) {
+	c.Assert(AddPluginToManifest(c.MkDir(), "html-report"), NotNil)
+}
+
+func (s *MySuite) TestGetProjectLanguage(c *C) {
+	projectRoot := c.MkDir()
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{"Language": "java", "Plugins": []}`), NewFilePermissions)
+	os.MkdirAll(filepath.Join(projectRoot, "specs"), NewDirectoryPermissions)
+	os.Chdir(filepath.Join(projectRoot, "specs"))
+
+	language, err := GetProjectLanguage()
+
+	c.Assert(err, IsNil)
+	c.Assert(language, Equals, "java")
+}
+
+func (s *MySuite) TestGetProjectLanguageWithoutLanguage(c *C) {
+	projectRoot := c.MkDir()
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{"Plugins": []}`), NewFilePermissions)
+	os.Chdir(projectRoot)
+
+	_, err := GetProjectLanguage()
+
+	c.Assert(err, ErrorMatches, "Language is not set in .*")
+}
+
+func (s *MySuite) TestGetProjectLanguageWithInvalidManifest(c *C) {
+	projectRoot := c.MkDir()
+	os.WriteFile(filepath.Join(projectRoot, ManifestFile), []byte(`{`), NewFilePermissions)
+	os.Chdir(projectRoot)
+
+	_, err := GetProjectLanguage()
+
+	c.Assert(err, ErrorMatches, "Failed to parse [^ ]*manifest.json: unexpected end of JSON input")
+}
+
+func (s *MySuite) TestMarshalManifestValue(c *C) {
+	manifest := Manifest{Language: "java", Extra: map[string]json.RawMessage{"X": json.RawMessage("1")}}
+
+	data, err := json.Marshal(manifest)
+
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `{"Language":"java","Plugins":[],"X":1}`)
+	var roundTripped Manifest
+	c.Assert(json.Unmarshal(data, &roundTripped), IsNil)
+	c.Assert(roundTripped.Language, Equals, "java")
+	c.Assert(string(roundTripped.Extra["X"]), Equals, "1")
+}
+
 func (s *MySuite) TestGetDirInProject(c *C) {
 	os.Chdir(dummyProject)
 
@@ -163,11 +393,118 @@ func (s *MySuite) TestFindFilesInDirFiltersDirectoriesThatAreSkipped(c *C) {
 	c.Assert(len(foundConceptFiles), Equals, 3)
 }
 
+func (s *MySuite) TestFindFilesInDirReturnsSortedFiles(c *C) {
+	isSpec := func(filePath string) bool { return filepath.Ext(filePath) == ".spec" }
+	skipNone := func(p string, f os.FileInfo) bool { return false }
+	specsDir := filepath.Join(dummyProject, "specs")
+
+	first := FindFilesInDir(specsDir, isSpec, skipNone)
+	second := FindFilesInDir(specsDir, isSpec, skipNone)
+
+	c.Assert(first, DeepEquals, []string{
+		filepath.Join(specsDir, "first.spec"),
+		filepath.Join(specsDir, "nested", "deep_nested", "deep_nested.spec"),
+		filepath.Join(specsDir, "nested", "nested.spec"),
+		filepath.Join(specsDir, "second.spec"),
+	})
+	c.Assert(second, DeepEquals, first)
+}
+
+func (s *MySuite) TestFindFilesInDirWithErrorForMissingDir(c *C) {
+	files, err := FindFilesInDirWithError("invalid", func(filePath string) bool {
+		return true
+	}, func(p string, f os.FileInfo) bool {
+		return false
+	})
+
+	c.Assert(err, NotNil)
+	c.Assert(len(files), Equals, 0)
+}
+
+func (s *MySuite) TestFindFilesInDirWithDepth(c *C) {
+	isSpec := func(filePath string) bool { return filepath.Ext(filePath) == ".spec" }
+	skipNone := func(p string, f os.FileInfo) bool { return false }
+	specsDir := filepath.Join(dummyProject, "specs")
+
+	c.Assert(len(FindFilesInDirWithDepth(specsDir, 0, isSpec, skipNone)), Equals, 2)
+	c.Assert(len(FindFilesInDirWithDepth(specsDir, 1, isSpec, skipNone)), Equals, 3)
+	c.Assert(len(FindFilesInDirWithDepth(specsDir, 2, isSpec, skipNone)), Equals, 4)
+}
+
+func (s *MySuite) TestFindFilesInDirWithDepthAppliesSkipDir(c *C) {
+	files := FindFilesInDirWithDepth(dummyProject, 1, func(filePath string) bool {
+		return filepath.Ext(filePath) == ".cpt"
+	}, func(p string, f os.FileInfo) bool {
+		return strings.HasPrefix(f.Name(), ".")
+	})
+
+	c.Assert(files, DeepEquals, []string{filepath.Join(dummyProject, "concepts", "first.cpt")})
+}
+
+func (s *MySuite) TestFindFilesByPattern(c *C) {
+	files, err := FindFilesByPattern(dummyProject, "*.cpt", "first.*")
+
+	c.Assert(err, IsNil)
+	c.Assert(files, DeepEquals, []string{
+		filepath.Join(dummyProject, "concepts", "first.cpt"),
+		filepath.Join(dummyProject, "concepts", "nested", "deep_nested", "deep_nested.cpt"),
+		filepath.Join(dummyProject, "concepts", "nested", "nested.cpt"),
+		filepath.Join(dummyProject, "specs", "first.spec"),
+	})
+}
+
+func (s *MySuite) TestFindFilesByPatternInHiddenDir(c *C) {
+	dir := filepath.Join(c.MkDir(), ".gauge")
+	os.MkdirAll(filepath.Join(dir, ".hidden"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(dir, "first.spec"), []byte(""), NewFilePermissions)
+	os.WriteFile(filepath.Join(dir, ".hidden", "second.spec"), []byte(""), NewFilePermissions)
+
+	files, err := FindFilesByPattern(dir, "*.spec")
+
+	c.Assert(err, IsNil)
+	c.Assert(files, DeepEquals, []string{filepath.Join(dir, "first.spec")})
+}
+
+func (s *MySuite) TestFindFilesByPatternWithInvalidPattern(c *C) {
+	_, err := FindFilesByPattern(dummyProject, "[")
+
+	c.Assert(err, NotNil)
+}
+
 func (s *MySuite) TestFileExists(c *C) {
 	c.Assert(FileExists(filepath.Join(dummyProject, ManifestFile)), Equals, true)
 	c.Assert(FileExists("invalid"), Equals, false)
 }
 
+func (s *MySuite) TestFileChecksum(c *C) {
+	file := filepath.Join(c.MkDir(), "archive.zip")
+	os.WriteFile(file, []byte("gauge"), NewFilePermissions)
+
+	sum, err := FileChecksum(file)
+
+	c.Assert(err, IsNil)
+	c.Assert(sum, Equals, "a90fd9a9a1e66597ae124f542f73ac08d3112e7d6f5e1781163be07ccae5be0d")
+}
+
+func (s *MySuite) TestFileMD5AndSHA1(c *C) {
+	file := filepath.Join(c.MkDir(), "archive.zip")
+	os.WriteFile(file, []byte("gauge"), NewFilePermissions)
+
+	md5Sum, err := FileMD5(file)
+	c.Assert(err, IsNil)
+	c.Assert(md5Sum, Equals, "37e1a9c3ba6042b79266687d13f3c5ff")
+
+	sha1Sum, err := FileSHA1(file)
+	c.Assert(err, IsNil)
+	c.Assert(sha1Sum, Equals, "7e0e01909734624f68a59471e1d9a3829c8a201d")
+}
+
+func (s *MySuite) TestFileChecksumForMissingFile(c *C) {
+	_, err := FileChecksum("invalid")
+
+	c.Assert(err, NotNil)
+}
+
 func (s *MySuite) TestGetDefaultPropertiesFile(c *C) {
 	os.Chdir(dummyProject)
 	envFile, err := GetDefaultPropertiesFile()
@@ -175,6 +512,138 @@ func (s *MySuite) TestGetDefaultPropertiesFile(c *C) {
 	c.Assert(envFile, Equals, filepath.Join(s.testDir, dummyProject, EnvDirectoryName, DefaultEnvDir, DefaultEnvFileName))
 }
 
+func (s *MySuite) TestLoadEnvProperties(c *C) {
+	os.Chdir(dummyProject)
+	envDir := filepath.Join(EnvDirectoryName, "ci")
+	os.MkdirAll(envDir, NewDirectoryPermissions)
+	defer os.RemoveAll(envDir)
+	os.WriteFile(filepath.Join(envDir, "a.properties"), []byte("browser = chrome\nheadless = false\n"), NewFilePermissions)
+	os.WriteFile(filepath.Join(envDir, "b.properties"), []byte("headless = true\n"), NewFilePermissions)
+	os.WriteFile(filepath.Join(envDir, "notes.txt"), []byte("ignored = true\n"), NewFilePermissions)
+
+	config, err := LoadEnvProperties("ci")
+
+	c.Assert(err, IsNil)
+	c.Assert(config["browser"], Equals, "chrome")
+	c.Assert(config["headless"], Equals, "true")
+	_, found := config["ignored"]
+	c.Assert(found, Equals, false)
+}
+
+func (s *MySuite) TestGetDefaultPropertiesFiles(c *C) {
+	os.Chdir(dummyProject)
+	defaultEnvDir := filepath.Join(EnvDirectoryName, DefaultEnvDir)
+	os.WriteFile(filepath.Join(defaultEnvDir, "browser.properties"), []byte("browser = chrome\n"), NewFilePermissions)
+	defer os.Remove(filepath.Join(defaultEnvDir, "browser.properties"))
+	os.WriteFile(filepath.Join(defaultEnvDir, "notes.txt"), []byte(""), NewFilePermissions)
+	defer os.Remove(filepath.Join(defaultEnvDir, "notes.txt"))
+
+	files, err := GetDefaultPropertiesFiles()
+
+	c.Assert(err, IsNil)
+	c.Assert(files, DeepEquals, []string{
+		filepath.Join(s.testDir, dummyProject, defaultEnvDir, "browser.properties"),
+		filepath.Join(s.testDir, dummyProject, defaultEnvDir, DefaultEnvFileName),
+	})
+}
+
+func (s *MySuite) TestGetDefaultPropertiesFilesWithoutDefaultEnvironment(c *C) {
+	projectDir := c.MkDir()
+	os.WriteFile(filepath.Join(projectDir, ManifestFile), []byte("{}"), NewFilePermissions)
+	os.MkdirAll(filepath.Join(projectDir, EnvDirectoryName), NewDirectoryPermissions)
+	os.Chdir(projectDir)
+
+	_, err := GetDefaultPropertiesFiles()
+
+	c.Assert(err, ErrorMatches, "Environment default does not exist: .*")
+}
+
+func (s *MySuite) TestLoadEnvPropertiesForMissingEnvironment(c *C) {
+	os.Chdir(dummyProject)
+
+	_, err := LoadEnvProperties("invalid")
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestLoadEnvPropertiesRejectsNamesOutsideEnvDirectory(c *C) {
+	os.Chdir(dummyProject)
+
+	for _, envName := range []string{"..", ".", "", filepath.Join("..", "specs"), filepath.Join("default", "..")} {
+		_, err := LoadEnvProperties(envName)
+		c.Assert(err, ErrorMatches, "Invalid environment name .*", Commentf(envName))
+	}
+}
+
+func (s *MySuite) TestAppendPropertiesAtomic(c *C) {
+	propertiesFile := filepath.Join(c.MkDir(), DefaultEnvFileName)
+	os.WriteFile(propertiesFile, []byte("existing = value\n"), 0600)
+
+	err := AppendPropertiesAtomic(propertiesFile, false, &Property{Name: "first", Comment: "firstComment", DefaultValue: "firstValue"}, &Property{Name: "second", DefaultValue: "secondValue"})
+
+	c.Assert(err, IsNil)
+	contents, _ := ReadFileContents(propertiesFile)
+	c.Assert(contents, Equals, "existing = value\n\n# firstComment\nfirst = firstValue\n\nsecond = secondValue\n")
+	entries, _ := os.ReadDir(filepath.Dir(propertiesFile))
+	c.Assert(len(entries), Equals, 1)
+	if runtime.GOOS != "windows" {
+		fi, _ := os.Stat(propertiesFile)
+		c.Assert(fi.Mode().Perm(), Equals, os.FileMode(0600))
+	}
+}
+
+func (s *MySuite) TestAppendPropertiesAtomicRejectsDuplicates(c *C) {
+	propertiesFile := filepath.Join(c.MkDir(), DefaultEnvFileName)
+	os.WriteFile(propertiesFile, []byte("# comment\nexisting = value\n"), NewFilePermissions)
+
+	err := AppendPropertiesAtomic(propertiesFile, true, &Property{Name: "new", DefaultValue: "1"}, &Property{Name: "existing", DefaultValue: "2"})
+	c.Assert(err, ErrorMatches, "Property existing already exists in .*")
+
+	err = AppendPropertiesAtomic(propertiesFile, true, &Property{Name: "new", DefaultValue: "1"}, &Property{Name: "new", DefaultValue: "2"})
+	c.Assert(err, ErrorMatches, "Property new already exists in .*")
+
+	contents, _ := ReadFileContents(propertiesFile)
+	c.Assert(contents, Equals, "# comment\nexisting = value\n")
+	c.Assert(AppendPropertiesAtomic(propertiesFile, false, &Property{Name: "existing", DefaultValue: "2"}), IsNil)
+}
+
+func (s *MySuite) TestAppendPropertiesAtomicIgnoresContinuationLinesWhenRejectingDuplicates(c *C) {
+	propertiesFile := filepath.Join(c.MkDir(), DefaultEnvFileName)
+	os.WriteFile(propertiesFile, []byte("paths = a,\\\n  foo\n"), NewFilePermissions)
+
+	err := AppendPropertiesAtomic(propertiesFile, true, &Property{Name: "foo", DefaultValue: "1"})
+	c.Assert(err, IsNil)
+
+	err = AppendPropertiesAtomic(propertiesFile, true, &Property{Name: "paths", DefaultValue: "b"})
+	c.Assert(err, ErrorMatches, "Property paths already exists in .*")
+}
+
+func (s *MySuite) TestListEnvironments(c *C) {
+	os.Chdir(dummyProject)
+	for _, env := range []string{"qa", "ci", ".hidden"} {
+		os.MkdirAll(filepath.Join(EnvDirectoryName, env), NewDirectoryPermissions)
+		defer os.RemoveAll(filepath.Join(EnvDirectoryName, env))
+	}
+	os.WriteFile(filepath.Join(EnvDirectoryName, "notes.txt"), []byte(""), NewFilePermissions)
+	defer os.Remove(filepath.Join(EnvDirectoryName, "notes.txt"))
+
+	envs, err := ListEnvironments()
+
+	c.Assert(err, IsNil)
+	c.Assert(envs, DeepEquals, []string{"ci", "default", "qa"})
+}
+
+func (s *MySuite) TestListEnvironmentsWithoutDefaultEnvironment(c *C) {
+	projectDir := c.MkDir()
+	os.WriteFile(filepath.Join(projectDir, ManifestFile), []byte("{}"), NewFilePermissions)
+	os.MkdirAll(filepath.Join(projectDir, EnvDirectoryName, "ci"), NewDirectoryPermissions)
+	os.Chdir(projectDir)
+
+	_, err := ListEnvironments()
+
+	c.Assert(err, ErrorMatches, "Default environment does not exist: .*")
+}
+
 func (s *MySuite) TestAppendingPropertiesToFile(c *C) {
 	os.Chdir(dummyProject)
 	defaultProperties, err := GetDefaultPropertiesFile()
@@ -193,6 +662,84 @@ func (s *MySuite) TestAppendingPropertiesToFile(c *C) {
 
 }
 
+func (s *MySuite) TestPropertyStringWithoutComment(c *C) {
+	property := &Property{Name: "first", DefaultValue: "value"}
+
+	c.Assert(property.String(), Equals, "first = value")
+}
+
+func (s *MySuite) TestPropertyStringWithComment(c *C) {
+	property := &Property{Name: "first", Comment: "first comment", DefaultValue: "value"}
+
+	c.Assert(property.String(), Equals, "# first comment\nfirst = value")
+}
+
+func (s *MySuite) TestPropertyStringWithMultiLineComment(c *C) {
+	property := &Property{Name: "first", Comment: "first line\nsecond line", DefaultValue: "value"}
+
+	c.Assert(property.String(), Equals, "# first line\n# second line\nfirst = value")
+}
+
+func (s *MySuite) TestReadProperty(c *C) {
+	file := filepath.Join(c.MkDir(), DefaultEnvFileName)
+	os.WriteFile(file, []byte(""), NewFilePermissions)
+	property := &Property{Name: "gauge_reports_dir", Comment: "The path to the gauge reports directory\nRelative to the project root", DefaultValue: "reports"}
+	AppendProperties(file, &Property{Name: "overwrite_reports", Comment: "Set as false to keep old reports", DefaultValue: "true"}, property)
+
+	actual, err := ReadProperty(file, "gauge_reports_dir")
+
+	c.Assert(err, IsNil)
+	c.Assert(actual, DeepEquals, property)
+}
+
+func (s *MySuite) TestReadPropertyWithContinuationLines(c *C) {
+	file := filepath.Join(c.MkDir(), DefaultEnvFileName)
+	os.WriteFile(file, []byte("# about paths\npaths = a,\\\n  name,\\\n  # not a comment\n# about name\nname = value\n"), NewFilePermissions)
+
+	actual, err := ReadProperty(file, "name")
+
+	c.Assert(err, IsNil)
+	c.Assert(actual, DeepEquals, &Property{Name: "name", Comment: "about name", DefaultValue: "value"})
+}
+
+func (s *MySuite) TestReadMissingProperty(c *C) {
+	file := filepath.Join(c.MkDir(), DefaultEnvFileName)
+	os.WriteFile(file, []byte("first = value\n"), NewFilePermissions)
+
+	_, err := ReadProperty(file, "second")
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestExpandProperties(c *C) {
+	os.Setenv("GAUGE_COMMON_TEST_DIR", "/home/gauge")
+	os.Setenv("GAUGE_COMMON_TEST_NESTED", "${GAUGE_COMMON_TEST_DIR}/nested")
+	os.Setenv("GAUGE_COMMON_TEST_LOOP", "a$GAUGE_COMMON_TEST_LOOP")
+	os.Setenv("GAUGE_COMMON_TEST_PW", "pa$word")
+	defer os.Unsetenv("GAUGE_COMMON_TEST_DIR")
+	defer os.Unsetenv("GAUGE_COMMON_TEST_NESTED")
+	defer os.Unsetenv("GAUGE_COMMON_TEST_LOOP")
+	defer os.Unsetenv("GAUGE_COMMON_TEST_PW")
+
+	expanded := ExpandProperties(properties.Properties{
+		"braces":    "${GAUGE_COMMON_TEST_DIR}/reports",
+		"plain":     "$GAUGE_COMMON_TEST_DIR/logs",
+		"nested":    "$GAUGE_COMMON_TEST_NESTED",
+		"undefined": "${GAUGE_COMMON_TEST_UNDEFINED}/reports",
+		"loop":      "$GAUGE_COMMON_TEST_LOOP",
+		"password":  "${GAUGE_COMMON_TEST_PW}",
+		"literal":   "reports",
+	})
+
+	c.Assert(expanded["braces"], Equals, "/home/gauge/reports")
+	c.Assert(expanded["plain"], Equals, "/home/gauge/logs")
+	c.Assert(expanded["nested"], Equals, "${GAUGE_COMMON_TEST_DIR}/nested")
+	c.Assert(expanded["undefined"], Equals, "/reports")
+	c.Assert(expanded["loop"], Equals, "a$GAUGE_COMMON_TEST_LOOP")
+	c.Assert(expanded["password"], Equals, "pa$word")
+	c.Assert(expanded["literal"], Equals, "reports")
+}
+
 func (s *MySuite) TestReadingContentsInUTF8WithoutSignature(c *C) {
 	filePath, _ := filepath.Abs(filepath.Join("_testdata", "utf8WithoutSig.csv"))
 
@@ -221,28 +768,166 @@ func (s *MySuite) TestReadingContentsInUTF8WithSignature(c *C) {
 	}
 }
 
-func (s *MySuite) TestGetProjectRootFromSpecPath(c *C) {
-	expectedRoot, _ := filepath.Abs(filepath.Join(dummyProject))
-	absProjPath, _ := filepath.Abs(dummyProject)
-	os.Chdir(os.TempDir())
+func (s *MySuite) TestReadingContentsInUTF16LittleEndianWithSignature(c *C) {
+	filePath, _ := filepath.Abs(filepath.Join("_testdata", "utf16LEWithSig.csv"))
 
-	root, err := GetProjectRootFromSpecPath(absProjPath + "/specs/")
+	contents, err := ReadFileContents(filePath)
 
-	c.Assert(err, IsNil)
-	c.Assert(root, Equals, expectedRoot)
+	c.Assert(err, Equals, nil)
+	c.Assert(contents, Equals, "word,count\ngauge,3\n")
 }
 
-func (s *MySuite) TestGetProjectRootGivesErrorWhenProvidedInvalidSpecFilePath(c *C) {
-	os.Chdir(os.TempDir())
+func (s *MySuite) TestReadingContentsInUTF16BigEndianWithSignature(c *C) {
+	filePath, _ := filepath.Abs(filepath.Join("_testdata", "utf16BEWithSig.csv"))
 
-	root, err := GetProjectRootFromSpecPath("/specs/nested/deep_nested/deep_nested.spec")
+	contents, err := ReadFileContents(filePath)
 
-	c.Assert(err.Error(), Equals, fmt.Sprintf("Failed to find Gauge project directory. Missing manifest.json file."))
-	c.Assert(root, Equals, "")
+	c.Assert(err, Equals, nil)
+	c.Assert(contents, Equals, "word,count\ngauge,3\n")
 }
 
-func (s *MySuite) TestGetProjectRootFromSpecFilePath(c *C) {
-	expectedRoot, _ := filepath.Abs(filepath.Join(dummyProject))
+func (s *MySuite) TestWriteFileContents(c *C) {
+	file := filepath.Join(c.MkDir(), "specs", "nested", "first.spec")
+
+	err := WriteFileContents(file, "# Specification")
+
+	c.Assert(err, IsNil)
+	bytes, _ := os.ReadFile(file)
+	c.Assert(string(bytes), Equals, "# Specification")
+}
+
+func (s *MySuite) TestWriteAndReadJSONFile(c *C) {
+	file := filepath.Join(c.MkDir(), "plugin", PluginJSONFile)
+	plugin := map[string]interface{}{"id": "java", "version": "0.10.0"}
+
+	err := WriteJSONFile(file, plugin)
+	c.Assert(err, IsNil)
+
+	contents, _ := ReadFileContents(file)
+	c.Assert(contents, Equals, "{\n  \"id\": \"java\",\n  \"version\": \"0.10.0\"\n}\n")
+
+	var actual map[string]interface{}
+	err = ReadJSONFile(file, &actual)
+	c.Assert(err, IsNil)
+	c.Assert(actual, DeepEquals, plugin)
+}
+
+func (s *MySuite) TestReadFileLines(c *C) {
+	filePath, _ := filepath.Abs(filepath.Join("_testdata", "utf8WithSig.csv"))
+
+	lines, err := ReadFileLines(filePath)
+
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{"word,count", "gauge,3"})
+}
+
+func (s *MySuite) TestReadFileContentsWithLimit(c *C) {
+	filePath, _ := filepath.Abs(filepath.Join("_testdata", "utf8WithSig.csv"))
+
+	contents, err := ReadFileContentsWithLimit(filePath, 1024)
+
+	c.Assert(err, IsNil)
+	c.Assert(strings.HasPrefix(contents, "word,count"), Equals, true)
+
+	_, err = ReadFileContentsWithLimit(filePath, 5)
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestReadFileContentsWithLimitErrors(c *C) {
+	dir := c.MkDir()
+	missing := filepath.Join(dir, "missing.csv")
+
+	_, err := ReadFileContentsWithLimit(missing, 1024)
+	c.Assert(errors.Is(err, ErrFileNotFound), Equals, true)
+
+	if isWindows() {
+		return
+	}
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, []byte("x"), NewFilePermissions)
+	_, err = ReadFileContentsWithLimit(filepath.Join(file, "child.csv"), 1024)
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, ErrFileNotFound), Equals, false)
+}
+
+func (s *MySuite) TestGetProjectRootFromSpecPath(c *C) {
+	expectedRoot, _ := filepath.Abs(filepath.Join(dummyProject))
+	absProjPath, _ := filepath.Abs(dummyProject)
+	os.Chdir(os.TempDir())
+
+	root, err := GetProjectRootFromSpecPath(absProjPath + "/specs/")
+
+	c.Assert(err, IsNil)
+	c.Assert(root, Equals, expectedRoot)
+}
+
+func (s *MySuite) TestRelPathToProject(c *C) {
+	absProjPath, _ := filepath.Abs(dummyProject)
+	os.Chdir(os.TempDir())
+
+	rel, err := RelPathToProject(filepath.Join(absProjPath, "specs", "nested", "deep_nested", "deep_nested.spec"))
+
+	c.Assert(err, IsNil)
+	c.Assert(rel, Equals, "specs/nested/deep_nested/deep_nested.spec")
+}
+
+func (s *MySuite) TestRelPathToProjectFromProjectDir(c *C) {
+	os.Chdir(filepath.Join(dummyProject, "specs"))
+
+	rel, err := RelPathToProject(filepath.Join("nested", "..", "nested", "deep_nested"))
+
+	c.Assert(err, IsNil)
+	c.Assert(rel, Equals, "specs/nested/deep_nested")
+}
+
+func (s *MySuite) TestRelPathToProjectForSpecOutsideProject(c *C) {
+	os.Chdir(dummyProject)
+
+	_, err := RelPathToProject(os.TempDir())
+
+	c.Assert(err, ErrorMatches, ".* is not inside the project .*")
+}
+
+func (s *MySuite) TestNotFoundErrorsWrapSentinelErrors(c *C) {
+	_, err := GetProjectRootFromDir(c.MkDir())
+	c.Assert(errors.Is(err, ErrProjectNotFound), Equals, true)
+	c.Assert(err.Error(), Equals, "Failed to find Gauge project directory. Missing manifest.json file.")
+
+	missing := filepath.Join(c.MkDir(), "missing.spec")
+	_, err = ReadFileContents(missing)
+	c.Assert(errors.Is(err, ErrFileNotFound), Equals, true)
+	c.Assert(err.Error(), Equals, fmt.Sprintf("File %s doesn't exist.", missing))
+
+	err = CopyFile(missing, filepath.Join(c.MkDir(), "copy.spec"))
+	c.Assert(errors.Is(err, ErrFileNotFound), Equals, true)
+}
+
+func (s *MySuite) TestGetProjectRootWhenWorkingDirIsRemoved(c *C) {
+	if isWindows() {
+		c.Skip("the working directory cannot be removed on windows")
+	}
+	dir := filepath.Join(c.MkDir(), "removed")
+	os.MkdirAll(dir, NewDirectoryPermissions)
+	os.Chdir(dir)
+	os.RemoveAll(dir)
+
+	_, err := GetProjectRoot()
+
+	c.Assert(errors.Is(err, ErrProjectNotFound), Equals, true)
+}
+
+func (s *MySuite) TestGetProjectRootGivesErrorWhenProvidedInvalidSpecFilePath(c *C) {
+	os.Chdir(os.TempDir())
+
+	root, err := GetProjectRootFromSpecPath("/specs/nested/deep_nested/deep_nested.spec")
+
+	c.Assert(err.Error(), Equals, fmt.Sprintf("Failed to find Gauge project directory. Missing manifest.json file."))
+	c.Assert(root, Equals, "")
+}
+
+func (s *MySuite) TestGetProjectRootFromSpecFilePath(c *C) {
+	expectedRoot, _ := filepath.Abs(filepath.Join(dummyProject))
 	absProjPath, _ := filepath.Abs(dummyProject)
 	os.Chdir(os.TempDir())
 
@@ -268,6 +953,10 @@ func (s *MySuite) TestGetExecutableCommand(c *C) {
 	logger1 := createLogger("logger1")
 	logger2 := createLogger("logger2")
 	command := "gauge"
+	expectedPath := command
+	if path, err := exec.LookPath(command); err == nil {
+		expectedPath = path
+	}
 
 	cmd := prepareCommand(false, []string{command, "-v", "-d"}, workingDirectory, logger1, logger2)
 
@@ -279,7 +968,7 @@ func (s *MySuite) TestGetExecutableCommand(c *C) {
 
 	c.Assert(wd, Equals, pd)
 	c.Assert(cmd, NotNil)
-	c.Assert(cmd.Path, Equals, command)
+	c.Assert(cmd.Path, Equals, expectedPath)
 	c.Assert(cmd.Dir, Equals, workingDirectory)
 	c.Assert(logger1.equals(cmd.Stdout.(logger)), Equals, true)
 	c.Assert(logger2.equals(cmd.Stderr.(logger)), Equals, true)
@@ -287,6 +976,15 @@ func (s *MySuite) TestGetExecutableCommand(c *C) {
 	c.Assert(args["-d"], Equals, true)
 }
 
+func (s *MySuite) TestGetExecutableCommandResolvesCommandNamesInPath(c *C) {
+	expectedPath, _ := exec.LookPath("go")
+
+	cmd := GetExecutableCommand(false, "go", "version")
+
+	c.Assert(cmd.Path, Equals, expectedPath)
+	c.Assert(cmd.Args, DeepEquals, []string{"go", "version"})
+}
+
 func (s *MySuite) TestGetExecutableCommandForCommandsWithPath(c *C) {
 	wd, _ := os.Getwd()
 
@@ -327,27 +1025,1898 @@ func (s *MySuite) TestGetExecutableCommandForSystemCommands(c *C) {
 	c.Assert(cmd.Path, Equals, expectedCommand.Path)
 }
 
-func (s *MySuite) TestGetGaugeHomeDirectory(c *C) {
-	path := "value string"
-	os.Setenv(GaugeHome, path)
+func (s *MySuite) TestSetEnvVariableSkipsEmptyValues(c *C) {
+	os.Setenv("GAUGE_COMMON_TEST_VAR", "value")
+	defer os.Unsetenv("GAUGE_COMMON_TEST_VAR")
 
-	home, err := GetGaugeHomeDirectory()
+	err := SetEnvVariable("GAUGE_COMMON_TEST_VAR", "")
 
-	c.Assert(err, Equals, nil)
-	c.Assert(home, Equals, path)
+	c.Assert(err, IsNil)
+	c.Assert(os.Getenv("GAUGE_COMMON_TEST_VAR"), Equals, "value")
 }
 
-func (s *MySuite) TestGetGaugeHomeDirectoryWhen_GAUGE_HOME_IsNotSet(c *C) {
-	os.Setenv(GaugeHome, "")
+func (s *MySuite) TestSetEnvVariableAllowEmpty(c *C) {
+	os.Setenv("GAUGE_COMMON_TEST_VAR", "value")
+	defer os.Unsetenv("GAUGE_COMMON_TEST_VAR")
 
-	home, err := GetGaugeHomeDirectory()
+	err := SetEnvVariableAllowEmpty("GAUGE_COMMON_TEST_VAR", "")
 
-	c.Assert(err, Equals, nil)
-	if isWindows() {
-		c.Assert(home, Equals, filepath.Join(os.Getenv(appData), ProductName))
-	} else {
-		c.Assert(home, Equals, filepath.Join(os.Getenv("HOME"), DotGauge))
+	c.Assert(err, IsNil)
+	value, ok := os.LookupEnv("GAUGE_COMMON_TEST_VAR")
+	c.Assert(ok, Equals, true)
+	c.Assert(value, Equals, "")
+}
+
+func (s *MySuite) TestExecuteCommandCollectOutput(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+
+	stdout, stderr, exitCode, err := ExecuteCommandCollectOutput(helperCommand("out", "err", "3"), "")
+
+	c.Assert(err, IsNil)
+	c.Assert(stdout, Equals, "out")
+	c.Assert(stderr, Equals, "err")
+	c.Assert(exitCode, Equals, 3)
+}
+
+func (s *MySuite) TestExecuteCommandCollectOutputForMissingCommand(c *C) {
+	_, _, exitCode, err := ExecuteCommandCollectOutput([]string{filepath.Join(c.MkDir(), "invalid")}, "")
+
+	c.Assert(err, NotNil)
+	c.Assert(exitCode, Equals, -1)
+}
+
+func (s *MySuite) TestExecuteCommandWithContextKillsCommandOnTimeout(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	cmd, err := ExecuteCommandWithContext(ctx, helperCommand("out", "err", "0", "10s"), "", io.Discard, io.Discard)
+	c.Assert(err, IsNil)
+
+	start := time.Now()
+	err = cmd.Wait()
+
+	c.Assert(err, NotNil)
+	c.Assert(ctx.Err(), Equals, context.DeadlineExceeded)
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+}
+
+func (s *MySuite) TestMergeEnv(c *C) {
+	env := mergeEnv([]string{"PATH=/usr/bin", "GAUGE_PORT=1234", "HOME=/home/gauge"}, map[string]string{"GAUGE_PORT": "5678", "GAUGE_API_PORT": "9090"})
+
+	c.Assert(env, DeepEquals, []string{"PATH=/usr/bin", "HOME=/home/gauge", "GAUGE_API_PORT=9090", "GAUGE_PORT=5678"})
+}
+
+func (s *MySuite) TestExecuteCommandWithExtraEnv(c *C) {
+	var stdout bytes.Buffer
+
+	cmd, err := ExecuteCommandWithExtraEnv(helperCommand("out", "err", "0"), "", &stdout, io.Discard, map[string]string{helperProcessEnv: "1"})
+	c.Assert(err, IsNil)
+	c.Assert(cmd.Wait(), IsNil)
+
+	c.Assert(stdout.String(), Equals, "out")
+	c.Assert(len(cmd.Env) > 1, Equals, true)
+}
+
+func (s *MySuite) TestExecuteCommandStreamingLines(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+	var stdout, stderr []string
+
+	cmd, err := ExecuteCommandStreamingLines(helperCommand("first\nsecond\nlast", "error", "0"), "", func(line string) {
+		stdout = append(stdout, line)
+	}, func(line string) {
+		stderr = append(stderr, line)
+	})
+	c.Assert(err, IsNil)
+	c.Assert(cmd.Wait(), IsNil)
+
+	c.Assert(stdout, DeepEquals, []string{"first", "second", "last"})
+	c.Assert(stderr, DeepEquals, []string{"error"})
+}
+
+func (s *MySuite) TestLineReaderJoinsLinesSplitAcrossWrites(c *C) {
+	var lines []string
+	l := newLineReader(func(line string) { lines = append(lines, line) })
+
+	l.Write([]byte("fir"))
+	l.Write([]byte("st\nsec"))
+	l.Write([]byte("ond\r\nla"))
+	n, err := l.ReadFrom(strings.NewReader("st"))
+
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(2))
+	c.Assert(lines, DeepEquals, []string{"first", "second", "last"})
+}
+
+func (s *MySuite) TestLineReaderDrainsLinesLongerThanMaxLineSize(c *C) {
+	var lines []string
+	l := newLineReader(func(line string) { lines = append(lines, line) })
+	long := strings.Repeat("a", maxLineSize+10)
+
+	n, err := l.ReadFrom(strings.NewReader(long + "\nnext\n"))
+
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(len(long)+6))
+	c.Assert(lines, DeepEquals, []string{long[:maxLineSize], long[maxLineSize:], "next"})
+}
+
+func (s *MySuite) TestExecuteCommandWithLineFilter(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+	var out bytes.Buffer
+
+	cmd, err := ExecuteCommandWithLineFilter(helperCommand("debug: a\nresult: 1\ndebug: b\nresult: 2", "result: error", "0"), "", func(line string) bool {
+		return strings.HasPrefix(line, "result:")
+	}, &out)
+	c.Assert(err, IsNil)
+	c.Assert(cmd.Wait(), IsNil)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	sort.Strings(lines)
+	c.Assert(lines, DeepEquals, []string{"result: 1", "result: 2", "result: error"})
+}
+
+func (s *MySuite) TestWaitWithTimeout(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+
+	cmd, err := ExecuteCommand(helperCommand("out", "err", "3"), "", io.Discard, io.Discard)
+	c.Assert(err, IsNil)
+	err = WaitWithTimeout(cmd, 10*time.Second)
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, ErrCommandTimeout), Equals, false)
+	c.Assert(cmd.ProcessState.ExitCode(), Equals, 3)
+}
+
+func (s *MySuite) TestWaitWithTimeoutKillsCommand(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+	cmd, err := ExecuteCommand(helperCommand("out", "err", "0", "10s"), "", io.Discard, io.Discard)
+	c.Assert(err, IsNil)
+	start := time.Now()
+
+	err = WaitWithTimeout(cmd, 100*time.Millisecond)
+
+	c.Assert(errors.Is(err, ErrCommandTimeout), Equals, true)
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+	c.Assert(cmd.ProcessState, NotNil)
+}
+
+func (s *MySuite) TestKillProcessGroup(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+	cmd, err := ExecuteCommandInProcessGroup(helperCommand("out", "err", "0", "10s"), "", io.Discard, io.Discard)
+	c.Assert(err, IsNil)
+
+	err = KillProcessGroup(cmd)
+	c.Assert(err, IsNil)
+
+	c.Assert(cmd.Wait(), NotNil)
+}
+
+func (s *MySuite) TestKillProcessGroupForProcessNotLeadingAGroup(c *C) {
+	cmd := exec.Command(os.Args[0], "out", "err", "0", "10s")
+	cmd.Env = append(os.Environ(), helperProcessEnv+"=1")
+	c.Assert(cmd.Start(), IsNil)
+
+	err := KillProcessGroup(cmd)
+	c.Assert(err, IsNil)
+
+	c.Assert(cmd.Wait(), NotNil)
+}
+
+func (s *MySuite) TestKillProcessGroupForCommandNotStarted(c *C) {
+	err := KillProcessGroup(GetExecutableCommand(false, "gauge"))
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestResolveExecutable(c *C) {
+	expected, _ := exec.LookPath("go")
+
+	path, err := ResolveExecutable("go")
+
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, expected)
+}
+
+func (s *MySuite) TestResolveMissingExecutable(c *C) {
+	_, err := ResolveExecutable("gauge_invalid_executable")
+
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "not found in PATH"), Equals, true)
+}
+
+func (s *MySuite) TestGetUniqueIDIsIncreasing(c *C) {
+	previous := GetUniqueID()
+	for i := 0; i < 1000; i++ {
+		id := GetUniqueID()
+		c.Assert(id > previous, Equals, true)
+		previous = id
+	}
+}
+
+func (s *MySuite) TestGetTempDirReturnsNewDirectoryOnEveryCall(c *C) {
+	dirs := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		dirs[GetTempDir()] = true
+	}
+	defer CleanupTempDirs()
+
+	c.Assert(len(dirs), Equals, 100)
+}
+
+func (s *MySuite) TestSaveGaugeConfigurationPreservesOrderAndComments(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	configDir := filepath.Join(gaugeHome, "config")
+	os.MkdirAll(configDir, NewDirectoryPermissions)
+	propertiesFile := filepath.Join(configDir, GaugePropertiesFile)
+	os.WriteFile(propertiesFile, []byte("# telemetry\ngauge_telemetry_enabled = true\n\n# timeout\nruntime_build_timeout = 100\nremoved = x\n"), NewFilePermissions)
+
+	err := SaveGaugeConfiguration(properties.Properties{"runtime_build_timeout": "200", "gauge_telemetry_enabled": "false", "added": "y"})
+
+	c.Assert(err, IsNil)
+	contents, err := ReadFileContents(propertiesFile)
+	c.Assert(err, IsNil)
+	c.Assert(contents, Equals, "# telemetry\ngauge_telemetry_enabled = false\n\n# timeout\nruntime_build_timeout = 200\nadded = y\n")
+	config, err := GetGaugeConfigurationFor(GaugePropertiesFile)
+	c.Assert(err, IsNil)
+	c.Assert(config["runtime_build_timeout"], Equals, "200")
+}
+
+func (s *MySuite) TestSaveGaugeConfigurationEscapesKeysAndValues(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	saved := properties.Properties{
+		"path":          `C:\gauge\bin`,
+		"key with=sep:": "value",
+		"#not_comment":  "x",
+		"padded":        "  leading spaces",
+		"url":           "http://host/?a=b",
+		"multiline":     "first\nsecond",
+	}
+
+	err := SaveGaugeConfiguration(saved)
+
+	c.Assert(err, IsNil)
+	config, err := GetGaugeConfigurationFor(GaugePropertiesFile)
+	c.Assert(err, IsNil)
+	c.Assert(config, DeepEquals, saved)
+}
+
+func (s *MySuite) TestSaveGaugeConfigurationHandlesContinuationLinesAndRemovedComments(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	configDir := filepath.Join(gaugeHome, "config")
+	os.MkdirAll(configDir, NewDirectoryPermissions)
+	propertiesFile := filepath.Join(configDir, GaugePropertiesFile)
+	os.WriteFile(propertiesFile, []byte("# header\n\n# about removed\n# more about removed\nremoved = a, \\\n  b\n# about kept\nkept = 1, \\\n  2\n"), NewFilePermissions)
+
+	err := SaveGaugeConfiguration(properties.Properties{"kept": "3"})
+
+	c.Assert(err, IsNil)
+	contents, err := ReadFileContents(propertiesFile)
+	c.Assert(err, IsNil)
+	c.Assert(contents, Equals, "# header\n\n# about kept\nkept = 3\n")
+}
+
+func (s *MySuite) TestSaveGaugeConfigurationCreatesFile(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+
+	err := SaveGaugeConfiguration(properties.Properties{"b": "2", "a": "1"})
+
+	c.Assert(err, IsNil)
+	contents, err := ReadFileContents(filepath.Join(gaugeHome, "config", GaugePropertiesFile))
+	c.Assert(err, IsNil)
+	c.Assert(contents, Equals, "a = 1\nb = 2\n")
+}
+
+func (s *MySuite) TestGetMergedGaugeConfigurationUserValuesWin(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, "config"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(gaugeHome, "config", GaugePropertiesFile), []byte("a = shared\nb = shared\n"), NewFilePermissions)
+	os.WriteFile(filepath.Join(gaugeHome, GaugePropertiesFile), []byte("b = user\nc = user\n"), NewFilePermissions)
+
+	config, err := GetMergedGaugeConfiguration()
+
+	c.Assert(err, IsNil)
+	c.Assert(config, DeepEquals, properties.Properties{"a": "shared", "b": "user", "c": "user"})
+	shared, err := GetGaugeConfigurationFor(GaugePropertiesFile)
+	c.Assert(err, IsNil)
+	c.Assert(shared, DeepEquals, properties.Properties{"a": "shared", "b": "shared"})
+}
+
+func (s *MySuite) TestGetMergedGaugeConfigurationWithoutUserFile(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, "config"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(gaugeHome, "config", GaugePropertiesFile), []byte("a = shared\n"), NewFilePermissions)
+
+	config, err := GetMergedGaugeConfiguration()
+
+	c.Assert(err, IsNil)
+	c.Assert(config, DeepEquals, properties.Properties{"a": "shared"})
+}
+
+func (s *MySuite) TestRemoveContentsKeepsDirectory(c *C) {
+	dir := c.MkDir()
+	os.MkdirAll(filepath.Join(dir, "sub", "nested"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(dir, "a.log"), []byte("a"), NewFilePermissions)
+	os.WriteFile(filepath.Join(dir, "sub", "b.log"), []byte("b"), NewFilePermissions)
+
+	err := RemoveContents(dir)
+
+	c.Assert(err, IsNil)
+	c.Assert(DirExists(dir), Equals, true)
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 0)
+	c.Assert(RemoveContents(dir), IsNil)
+}
+
+func (s *MySuite) TestRemoveContentsOfMissingDirectory(c *C) {
+	dir := filepath.Join(c.MkDir(), "missing")
+
+	err := RemoveContents(dir)
+
+	c.Assert(err, ErrorMatches, "Directory .* does not exist")
+}
+
+func (s *MySuite) TestDirSize(c *C) {
+	dir := c.MkDir()
+	os.MkdirAll(filepath.Join(dir, "sub"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(dir, "a"), []byte("12345"), NewFilePermissions)
+	os.WriteFile(filepath.Join(dir, "sub", "b"), []byte("123"), NewFilePermissions)
+	if runtime.GOOS != "windows" {
+		os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "link"))
 	}
+
+	size, err := DirSize(dir)
+
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(8))
+}
+
+func (s *MySuite) TestDirSizeOfMissingPath(c *C) {
+	_, err := DirSize(filepath.Join(c.MkDir(), "missing"))
+
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, os.ErrNotExist), Equals, true)
+}
+
+func (s *MySuite) TestIsWritableDir(c *C) {
+	dir := c.MkDir()
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, []byte("x"), NewFilePermissions)
+
+	c.Assert(IsWritableDir(dir), Equals, true)
+	entries, _ := os.ReadDir(dir)
+	c.Assert(len(entries), Equals, 1)
+	c.Assert(IsWritableDir(file), Equals, false)
+	c.Assert(IsWritableDir(filepath.Join(dir, "missing")), Equals, false)
+}
+
+func (s *MySuite) TestEnsureDir(c *C) {
+	dir := filepath.Join(c.MkDir(), "a", "b")
+
+	c.Assert(EnsureDir(dir), IsNil)
+	c.Assert(DirExists(dir), Equals, true)
+	c.Assert(EnsureDir(dir), IsNil)
+}
+
+func (s *MySuite) TestEnsureDirFailsWhenPathIsAFile(c *C) {
+	file := filepath.Join(c.MkDir(), "file")
+	os.WriteFile(file, []byte("x"), NewFilePermissions)
+
+	err := EnsureDir(file)
+
+	c.Assert(err, ErrorMatches, "Failed to create directory .*. A file with the same name exists")
+}
+
+func (s *MySuite) TestSafeJoin(c *C) {
+	base := c.MkDir()
+
+	path, err := SafeJoin(base, filepath.Join("plugins", "java"))
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, filepath.Join(base, "plugins", "java"))
+
+	path, err = SafeJoin(base, "a/../b")
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, filepath.Join(base, "b"))
+
+	path, err = SafeJoin(base, "..foo")
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, filepath.Join(base, "..foo"))
+}
+
+func (s *MySuite) TestSafeJoinRejectsEscapingPaths(c *C) {
+	base := c.MkDir()
+
+	for _, untrusted := range []string{"..", "../evil", "a/../../evil", filepath.Join("..", filepath.Base(base)+"-evil"), "/etc/passwd"} {
+		_, err := SafeJoin(base, untrusted)
+		c.Assert(err, NotNil, Commentf("expected %s to be rejected", untrusted))
+	}
+}
+
+func (s *MySuite) TestPathToFileURLRoundTrip(c *C) {
+	path := filepath.Join(c.MkDir(), "my specs#1", "a?b.spec")
+
+	uri, err := PathToFileURL(path)
+	c.Assert(err, IsNil)
+	c.Assert(strings.HasPrefix(uri, "file:///"), Equals, true)
+	c.Assert(strings.HasSuffix(uri, "/my%20specs%231/a%3Fb.spec"), Equals, true)
+
+	roundTripped, err := FileURLToPath(uri)
+	c.Assert(err, IsNil)
+	c.Assert(roundTripped, Equals, path)
+}
+
+func (s *MySuite) TestFileURLToPathWithInvalidURL(c *C) {
+	_, err := FileURLToPath("https://gauge.org/specs/a.spec")
+	c.Assert(err, ErrorMatches, "Invalid file URL https://gauge.org/specs/a.spec. Scheme must be file")
+
+	_, err = FileURLToPath("file://%zz")
+	c.Assert(err, ErrorMatches, "Invalid file URL file://%zz. .*")
+}
+
+func (s *MySuite) TestIsInProject(c *C) {
+	root := filepath.Join(c.MkDir(), "project")
+
+	c.Assert(IsInProject(root, root), Equals, true)
+	c.Assert(IsInProject(root, filepath.Join(root, "specs", "a.spec")), Equals, true)
+	c.Assert(IsInProject(root+string(filepath.Separator), filepath.Join(root, "specs")), Equals, true)
+	c.Assert(IsInProject(root, filepath.Join(root, "specs", "..", "..", "other")), Equals, false)
+	c.Assert(IsInProject(root, root+"-other"), Equals, false)
+	c.Assert(IsInProject(root, filepath.Dir(root)), Equals, false)
+}
+
+func (s *MySuite) TestIsInProjectIsCaseInsensitiveOnWindowsAndMac(c *C) {
+	root := filepath.Join(c.MkDir(), "project")
+	expected := runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+	c.Assert(IsInProject(root, filepath.Join(strings.ToUpper(root), "a.spec")), Equals, expected)
+}
+
+func (s *MySuite) TestGetConfiguredPort(c *C) {
+	defer os.Unsetenv(GaugePortEnvName)
+
+	os.Unsetenv(GaugePortEnvName)
+	port, err := GetConfiguredPort(GaugePortEnvName)
+	c.Assert(err, IsNil)
+	c.Assert(port, Equals, 0)
+
+	os.Setenv(GaugePortEnvName, " 8080 ")
+	port, err = GetConfiguredPort(GaugePortEnvName)
+	c.Assert(err, IsNil)
+	c.Assert(port, Equals, 8080)
+}
+
+func (s *MySuite) TestGetConfiguredPortWithInvalidValue(c *C) {
+	defer os.Unsetenv(GaugePortEnvName)
+
+	for _, value := range []string{"abc", "-1", "65536"} {
+		os.Setenv(GaugePortEnvName, value)
+		_, err := GetConfiguredPort(GaugePortEnvName)
+		c.Assert(err, ErrorMatches, "Invalid port GAUGE_PORT = .*. Port must be a number between 0 and 65535")
+	}
+}
+
+func (s *MySuite) TestGetFreePort(c *C) {
+	port, err := GetFreePort()
+
+	c.Assert(err, IsNil)
+	c.Assert(port > 0, Equals, true)
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	c.Assert(err, IsNil)
+	listener.Close()
+}
+
+func (s *MySuite) TestReadFileContentsNormalized(c *C) {
+	file := filepath.Join(c.MkDir(), "mixed.spec")
+	os.WriteFile(file, []byte("\xef\xbb\xbfa\r\nb\rc\nd\r\n"), NewFilePermissions)
+
+	contents, err := ReadFileContentsNormalized(file)
+	c.Assert(err, IsNil)
+	c.Assert(contents, Equals, "a\nb\nc\nd\n")
+
+	contents, err = ReadFileContents(file)
+	c.Assert(err, IsNil)
+	c.Assert(contents, Equals, "a\r\nb\rc\nd\r\n")
+}
+
+func (s *MySuite) TestGetPropertyWithDefaults(c *C) {
+	p := properties.Properties{"name": "gauge", "empty": "", "enabled": " Yes ", "disabled": "0", "flag": "maybe", "timeout": " 30 ", "retries": "many"}
+
+	c.Assert(GetStringProperty(p, "name", "def"), Equals, "gauge")
+	c.Assert(GetStringProperty(p, "empty", "def"), Equals, "")
+	c.Assert(GetStringProperty(p, "missing", "def"), Equals, "def")
+
+	c.Assert(GetBoolProperty(p, "enabled", false), Equals, true)
+	c.Assert(GetBoolProperty(p, "disabled", true), Equals, false)
+	c.Assert(GetBoolProperty(p, "flag", true), Equals, true)
+	c.Assert(GetBoolProperty(p, "missing", true), Equals, true)
+
+	c.Assert(GetIntProperty(p, "timeout", 10), Equals, 30)
+	c.Assert(GetIntProperty(p, "retries", 3), Equals, 3)
+	c.Assert(GetIntProperty(p, "missing", 5), Equals, 5)
+}
+
+func (s *MySuite) TestIsPluginCompatible(c *C) {
+	tests := []struct {
+		constraint   string
+		gaugeVersion string
+		compatible   bool
+	}{
+		{"1.0.0", "1.0.0", true},
+		{"1.0.0", "0.9.9", false},
+		{">=1.0.0 <2.0.0", "1.5.3", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{">1.0.0", "1.0.0", false},
+		{"<=1.2.0", "1.2.0", true},
+		{"=1.2.0", "1.2.1", false},
+		{">=1.0.0", "1.0.0-beta", false},
+		{">= 1.0.0 < 2.0.0", "1.5.3", true},
+		{">= 1.0.0 < 2.0.0", "2.0.0", false},
+		{">=1.0.0", "1.0.0.nightly-2024-01-01", true},
+		{"<1.0.0", "1.0.0.nightly-2024-01-01", false},
+	}
+	for _, t := range tests {
+		compatible, err := IsPluginCompatible(map[string]interface{}{"gaugeVersion": t.constraint}, t.gaugeVersion)
+		c.Assert(err, IsNil)
+		c.Assert(compatible, Equals, t.compatible, Commentf("%s with gauge %s", t.constraint, t.gaugeVersion))
+	}
+}
+
+func (s *MySuite) TestIsPluginCompatibleWithoutConstraint(c *C) {
+	compatible, err := IsPluginCompatible(map[string]interface{}{"id": "java"}, "1.0.0")
+
+	c.Assert(err, IsNil)
+	c.Assert(compatible, Equals, true)
+}
+
+func (s *MySuite) TestIsPluginCompatibleWithInvalidConstraint(c *C) {
+	_, err := IsPluginCompatible(map[string]interface{}{"gaugeVersion": "=>1.0.0"}, "1.0.0")
+	c.Assert(err, ErrorMatches, "Invalid gaugeVersion constraint =>1.0.0")
+
+	_, err = IsPluginCompatible(map[string]interface{}{"gaugeVersion": ">=1.0"}, "1.0.0")
+	c.Assert(err, ErrorMatches, "Invalid gaugeVersion constraint >=1.0. Invalid version 1.0")
+
+	_, err = IsPluginCompatible(map[string]interface{}{"gaugeVersion": ">= "}, "1.0.0")
+	c.Assert(err, ErrorMatches, "Invalid gaugeVersion constraint >= . Invalid version ")
+
+	_, err = IsPluginCompatible(map[string]interface{}{"gaugeVersion": 1}, "1.0.0")
+	c.Assert(err, ErrorMatches, "Invalid plugin properties: gaugeVersion is not a string")
+}
+
+func (s *MySuite) TestLoadDotEnv(c *C) {
+	file := filepath.Join(c.MkDir(), ".env")
+	os.WriteFile(file, []byte("# comment\n\nBROWSER=chrome\n QUOTED = \"hello world\" \nexport EXPORTED=1\nSINGLE='a'\nURL=http://host/?a=b&c=d\nEMPTY=\nUNBALANCED=\"x\n"), NewFilePermissions)
+
+	env, err := LoadDotEnv(file)
+
+	c.Assert(err, IsNil)
+	c.Assert(env, DeepEquals, map[string]string{
+		"BROWSER":    "chrome",
+		"QUOTED":     "hello world",
+		"EXPORTED":   "1",
+		"SINGLE":     "a",
+		"URL":        "http://host/?a=b&c=d",
+		"EMPTY":      "",
+		"UNBALANCED": `"x`,
+	})
+}
+
+func (s *MySuite) TestLoadDotEnvWithInvalidLine(c *C) {
+	file := filepath.Join(c.MkDir(), ".env")
+	os.WriteFile(file, []byte("A=1\nnot a variable\n"), NewFilePermissions)
+
+	_, err := LoadDotEnv(file)
+
+	c.Assert(err, ErrorMatches, "Invalid line 2 in .*: not a variable")
+}
+
+func (s *MySuite) TestLoadDotEnvWithInvalidKey(c *C) {
+	file := filepath.Join(c.MkDir(), ".env")
+	os.WriteFile(file, []byte("A=1\nexport B=2\nMY KEY=3\n"), NewFilePermissions)
+
+	_, err := LoadDotEnv(file)
+
+	c.Assert(err, ErrorMatches, "Invalid line 3 in .*: MY KEY=3")
+}
+
+func (s *MySuite) TestApplyEnvMap(c *C) {
+	defer SnapshotEnv()()
+
+	err := ApplyEnvMap(map[string]string{"GAUGE_DOTENV_A": "1", "GAUGE_DOTENV_EMPTY": ""})
+
+	c.Assert(err, IsNil)
+	c.Assert(os.Getenv("GAUGE_DOTENV_A"), Equals, "1")
+	_, found := os.LookupEnv("GAUGE_DOTENV_EMPTY")
+	c.Assert(found, Equals, false)
+}
+
+func (s *MySuite) TestSnapshotEnvRestoresEnvironment(c *C) {
+	os.Setenv("GAUGE_SNAPSHOT_CHANGED", "original")
+	os.Setenv("GAUGE_SNAPSHOT_REMOVED", "original")
+	defer os.Unsetenv("GAUGE_SNAPSHOT_CHANGED")
+	defer os.Unsetenv("GAUGE_SNAPSHOT_REMOVED")
+	before := os.Environ()
+	sort.Strings(before)
+
+	restore := SnapshotEnv()
+	os.Setenv("GAUGE_SNAPSHOT_CHANGED", "changed")
+	os.Unsetenv("GAUGE_SNAPSHOT_REMOVED")
+	os.Setenv("GAUGE_SNAPSHOT_ADDED", "added")
+	restore()
+
+	after := os.Environ()
+	sort.Strings(after)
+	c.Assert(after, DeepEquals, before)
+	_, found := os.LookupEnv("GAUGE_SNAPSHOT_ADDED")
+	c.Assert(found, Equals, false)
+}
+
+func (s *MySuite) TestTailFile(c *C) {
+	file := filepath.Join(c.MkDir(), "runner.log")
+	os.WriteFile(file, []byte("one\ntwo\r\nthree\nfour\n"), NewFilePermissions)
+
+	lines, err := TailFile(file, 2)
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{"three", "four"})
+
+	lines, err = TailFile(file, 10)
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{"one", "two", "three", "four"})
+}
+
+func (s *MySuite) TestTailFileWithoutTrailingNewline(c *C) {
+	file := filepath.Join(c.MkDir(), "runner.log")
+	os.WriteFile(file, []byte("one\ntwo"), NewFilePermissions)
+
+	lines, err := TailFile(file, 1)
+
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{"two"})
+}
+
+func (s *MySuite) TestTailFileSpanningChunks(c *C) {
+	file := filepath.Join(c.MkDir(), "runner.log")
+	var contents strings.Builder
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&contents, "line %d\n", i)
+	}
+	os.WriteFile(file, []byte(contents.String()), NewFilePermissions)
+
+	lines, err := TailFile(file, 5000)
+
+	c.Assert(err, IsNil)
+	c.Assert(len(lines), Equals, 5000)
+	c.Assert(lines[0], Equals, "line 5000")
+	c.Assert(lines[4999], Equals, "line 9999")
+}
+
+func (s *MySuite) TestTailFileOfEmptyFile(c *C) {
+	file := filepath.Join(c.MkDir(), "runner.log")
+	os.WriteFile(file, []byte(""), NewFilePermissions)
+
+	lines, err := TailFile(file, 3)
+
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{})
+}
+
+func (s *MySuite) TestWatchFileDebouncesChanges(c *C) {
+	file := filepath.Join(c.MkDir(), "example.spec")
+	os.WriteFile(file, []byte("# Spec"), NewFilePermissions)
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan bool, 10)
+	done := make(chan error)
+	go func() {
+		done <- WatchFile(ctx, file, func() { changes <- true })
+	}()
+
+	time.Sleep(2 * watchPollInterval)
+	os.WriteFile(file, []byte("# Spec\n"), NewFilePermissions)
+	time.Sleep(watchPollInterval)
+	os.WriteFile(file, []byte("# Spec\n\n"), NewFilePermissions)
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		c.Fatal("change was not reported")
+	}
+	time.Sleep(2 * watchDebounce)
+	c.Assert(len(changes), Equals, 0)
+
+	cancel()
+	c.Assert(<-done, IsNil)
+}
+
+func (s *MySuite) TestWatchFileForMissingFile(c *C) {
+	err := WatchFile(context.Background(), filepath.Join(c.MkDir(), "missing.spec"), func() {})
+
+	c.Assert(err, ErrorMatches, "Failed to watch .*")
+}
+
+func (s *MySuite) TestInitializeProject(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	skelDir := filepath.Join(gaugeHome, "config", "skel")
+	os.MkdirAll(filepath.Join(skelDir, "java", "src"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(skelDir, "example.spec"), []byte("# Specification"), NewFilePermissions)
+	os.WriteFile(filepath.Join(skelDir, "java", "src", "StepImplementation.java"), []byte("class StepImplementation {}"), NewFilePermissions)
+	target := filepath.Join(c.MkDir(), "project")
+
+	created, err := InitializeProject(target, "java")
+
+	c.Assert(err, IsNil)
+	c.Assert(created, DeepEquals, []string{
+		filepath.Join(target, ManifestFile),
+		filepath.Join(target, "specs", "example.spec"),
+		filepath.Join(target, "src", "StepImplementation.java"),
+	})
+	c.Assert(DirExists(filepath.Join(target, "env", "default")), Equals, true)
+	manifest, err := ReadManifest(target)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.Language, Equals, "java")
+}
+
+func (s *MySuite) TestInitializeProjectInNonEmptyDirectory(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	target := c.MkDir()
+	os.WriteFile(filepath.Join(target, "README.md"), []byte("readme"), NewFilePermissions)
+
+	_, err := InitializeProject(target, "java")
+	c.Assert(err, ErrorMatches, "Failed to initialize project. .* is not empty")
+
+	created, err := InitializeProjectForce(target, "java")
+	c.Assert(err, IsNil)
+	c.Assert(created, DeepEquals, []string{filepath.Join(target, ManifestFile)})
+	c.Assert(FileExists(filepath.Join(target, "README.md")), Equals, true)
+}
+
+func (s *MySuite) TestAcquireLockBlocksUntilReleased(c *C) {
+	lockPath := filepath.Join(c.MkDir(), "install.lock")
+	release, err := AcquireLock(lockPath)
+	c.Assert(err, IsNil)
+
+	acquired := make(chan bool)
+	go func() {
+		releaseSecond, err := AcquireLock(lockPath)
+		if err == nil {
+			releaseSecond()
+		}
+		acquired <- err == nil
+	}()
+
+	select {
+	case <-acquired:
+		c.Fatal("lock acquired while still held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	c.Assert(release(), IsNil)
+	c.Assert(<-acquired, Equals, true)
+	c.Assert(release(), IsNil)
+}
+
+func (s *MySuite) TestAcquireLockUnderGaugeHome(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+
+	release, err := AcquireLock("install.lock")
+
+	c.Assert(err, IsNil)
+	c.Assert(FileExists(filepath.Join(gaugeHome, "install.lock")), Equals, true)
+	c.Assert(release(), IsNil)
+}
+
+func (s *MySuite) TestGetProcessTempDir(c *C) {
+	first := GetProcessTempDir()
+	second := GetProcessTempDir()
+
+	c.Assert(second, Equals, first)
+	c.Assert(DirExists(first), Equals, true)
+	c.Assert(GetTempDir(), Not(Equals), first)
+}
+
+func (s *MySuite) TestGetProcessTempDirIsCleanedUpAfterRecreation(c *C) {
+	dir := GetProcessTempDir()
+	c.Assert(CleanupTempDirs(), IsNil)
+	c.Assert(DirExists(dir), Equals, false)
+
+	c.Assert(GetProcessTempDir(), Equals, dir)
+	c.Assert(DirExists(dir), Equals, true)
+
+	c.Assert(CleanupTempDirs(), IsNil)
+	c.Assert(DirExists(dir), Equals, false)
+}
+
+func (s *MySuite) TestCleanupTempDirs(c *C) {
+	tempDir := GetTempDir()
+	registered := filepath.Join(os.TempDir(), fmt.Sprintf("gauge_registered%d", GetUniqueID()))
+	os.MkdirAll(registered, NewDirectoryPermissions)
+	RegisterTempDirForCleanup(registered)
+
+	err := CleanupTempDirs()
+
+	c.Assert(err, IsNil)
+	c.Assert(DirExists(tempDir), Equals, false)
+	c.Assert(DirExists(registered), Equals, false)
+}
+
+func (s *MySuite) TestOpenInDefaultAppWithoutOpener(c *C) {
+	if runtime.GOOS != "linux" {
+		c.Skip("xdg-open is only used on linux")
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	defer os.Setenv("PATH", path)
+
+	err := OpenInDefaultApp("report.html")
+
+	c.Assert(err, ErrorMatches, ".*xdg-open not found in PATH")
+}
+
+func (s *MySuite) TestGetInstallationPrefixFromRunningExecutable(c *C) {
+	prefix := c.MkDir()
+	executable := filepath.Join(prefix, "bin", ExecutableName())
+	os.MkdirAll(filepath.Join(prefix, "share", ProductName), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Dir(executable), NewDirectoryPermissions)
+	os.WriteFile(executable, []byte(""), 0755)
+	resetExecutablePrefix := func() {
+		executablePrefix, executablePrefixFound, executablePrefixOnce = "", false, sync.Once{}
+	}
+	defer func() {
+		executablePath = os.Executable
+		resetExecutablePrefix()
+	}()
+	executablePath = func() (string, error) { return executable, nil }
+	resetExecutablePrefix()
+
+	actual, err := GetInstallationPrefix()
+
+	c.Assert(err, IsNil)
+	expected, _ := filepath.EvalSymlinks(prefix)
+	c.Assert(actual, Equals, expected)
+
+	executablePath = func() (string, error) { return "", errors.New("executable should not be looked up again") }
+	cached, err := GetInstallationPrefix()
+
+	c.Assert(err, IsNil)
+	c.Assert(cached, Equals, expected)
+}
+
+func (s *MySuite) TestGetExecutableDir(c *C) {
+	dir, err := GetExecutableDir()
+
+	c.Assert(err, IsNil)
+	running, _ := os.Executable()
+	resolved, _ := filepath.EvalSymlinks(running)
+	c.Assert(dir, Equals, filepath.Dir(resolved))
+}
+
+func (s *MySuite) TestGetExecutableDirFollowsSymlinks(c *C) {
+	if isWindows() {
+		c.Skip("symlinks need elevated privileges on windows")
+	}
+	installDir := c.MkDir()
+	executable := filepath.Join(installDir, "runner")
+	os.WriteFile(executable, []byte(""), 0755)
+	link := filepath.Join(c.MkDir(), "runner")
+	os.Symlink(executable, link)
+	defer func() { executablePath = os.Executable }()
+	executablePath = func() (string, error) { return link, nil }
+
+	dir, err := GetExecutableDir()
+
+	c.Assert(err, IsNil)
+	expected, _ := filepath.EvalSymlinks(installDir)
+	c.Assert(dir, Equals, expected)
+}
+
+func (s *MySuite) TestGetGaugeExecutablePathFromGaugeRoot(c *C) {
+	root := c.MkDir()
+	executable := filepath.Join(root, "bin", ExecutableName())
+	os.MkdirAll(filepath.Dir(executable), NewDirectoryPermissions)
+	os.WriteFile(executable, []byte(""), 0755)
+	os.Setenv(GaugeRootEnv, root)
+	defer os.Unsetenv(GaugeRootEnv)
+
+	path, err := GetGaugeExecutablePath()
+
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, executable)
+}
+
+func (s *MySuite) TestGetGaugeExecutablePathWhenMissingFromInstallationPrefix(c *C) {
+	prefix := c.MkDir()
+	runner := filepath.Join(prefix, "bin", "runner")
+	os.MkdirAll(filepath.Join(prefix, "share", ProductName), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Dir(runner), NewDirectoryPermissions)
+	os.WriteFile(runner, []byte(""), 0755)
+	resetExecutablePrefix := func() {
+		executablePrefix, executablePrefixFound, executablePrefixOnce = "", false, sync.Once{}
+	}
+	defer func() {
+		executablePath = os.Executable
+		resetExecutablePrefix()
+	}()
+	executablePath = func() (string, error) { return runner, nil }
+	resetExecutablePrefix()
+	os.Unsetenv(GaugeRootEnv)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", "")
+
+	_, err := GetGaugeExecutablePath()
+
+	c.Assert(err, ErrorMatches, "Could not find gauge.* in \\$GAUGE_ROOT, PATH or installation prefix .*")
+}
+
+func (s *MySuite) TestGetGaugeHomeDirectory(c *C) {
+	path := "value string"
+	os.Setenv(GaugeHome, path)
+
+	home, err := GetGaugeHomeDirectory()
+
+	c.Assert(err, Equals, nil)
+	c.Assert(home, Equals, path)
+}
+
+func (s *MySuite) TestGetGaugeHomeDirectoryWhen_GAUGE_HOME_IsNotSet(c *C) {
+	os.Setenv(GaugeHome, "")
+
+	home, err := GetGaugeHomeDirectory()
+
+	c.Assert(err, Equals, nil)
+	if isWindows() {
+		c.Assert(home, Equals, filepath.Join(os.Getenv(appData), ProductName))
+	} else {
+		c.Assert(home, Equals, filepath.Join(os.Getenv("HOME"), DotGauge))
+	}
+}
+
+func (s *MySuite) TestCopyFilePreservesModeAndModTime(c *C) {
+	dir := c.MkDir()
+	src := filepath.Join(dir, "src.txt")
+	dest := filepath.Join(dir, "dest.txt")
+	os.WriteFile(src, []byte("contents"), 0600)
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(src, modTime, modTime)
+
+	err := CopyFile(src, dest)
+
+	c.Assert(err, IsNil)
+	srcInfo, _ := os.Stat(src)
+	destInfo, _ := os.Stat(dest)
+	c.Assert(destInfo.Mode(), Equals, srcInfo.Mode())
+	c.Assert(destInfo.ModTime().Equal(modTime), Equals, true)
+	contents, _ := ReadFileContents(dest)
+	c.Assert(contents, Equals, "contents")
+}
+
+func (s *MySuite) TestCopyFileStreamsLargeFiles(c *C) {
+	dir := c.MkDir()
+	src := filepath.Join(dir, "large.bin")
+	dest := filepath.Join(dir, "large_copy.bin")
+	size := int64(64 * 1024 * 1024)
+	f, _ := os.Create(src)
+	f.Truncate(size)
+	f.Close()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err := CopyFile(src, dest)
+	runtime.ReadMemStats(&after)
+
+	c.Assert(err, IsNil)
+	info, _ := os.Stat(dest)
+	c.Assert(info.Size(), Equals, size)
+	c.Assert(after.TotalAlloc-before.TotalAlloc < uint64(size/8), Equals, true)
+}
+
+func (s *MySuite) TestMirrorDirParallel(c *C) {
+	src, _ := filepath.Abs(dummyProject)
+	dst := c.MkDir()
+
+	expected, err := MirrorDir(src, c.MkDir())
+	c.Assert(err, IsNil)
+
+	files, err := MirrorDirParallel(src, dst, 4)
+
+	c.Assert(err, IsNil)
+	c.Assert(files, DeepEquals, expected)
+	for _, file := range files {
+		c.Assert(FileExists(filepath.Join(dst, file)), Equals, true)
+	}
+}
+
+func (s *MySuite) TestMirrorDirWithStats(c *C) {
+	src := c.MkDir()
+	dst := c.MkDir()
+	os.WriteFile(filepath.Join(src, "first.spec"), []byte("first"), NewFilePermissions)
+	os.WriteFile(filepath.Join(src, "second.spec"), []byte("second"), NewFilePermissions)
+	MirrorDir(src, dst)
+	os.WriteFile(filepath.Join(src, "second.spec"), []byte("second changed"), NewFilePermissions)
+
+	copied, skipped, err := MirrorDirWithStats(src, dst)
+
+	c.Assert(err, IsNil)
+	c.Assert(copied, DeepEquals, []string{"second.spec"})
+	c.Assert(skipped, DeepEquals, []string{"first.spec"})
+}
+
+func (s *MySuite) TestMirrorFileReturnsErrorForNonRegularFile(c *C) {
+	err := MirrorFile(c.MkDir(), filepath.Join(c.MkDir(), "dir"))
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestMirrorFileWithSymlinks(c *C) {
+	if isWindows() {
+		c.Skip("symlinks need elevated privileges on windows")
+	}
+	src := c.MkDir()
+	dst := c.MkDir()
+	os.WriteFile(filepath.Join(src, "first.spec"), []byte("spec"), NewFilePermissions)
+	os.Symlink("first.spec", filepath.Join(src, "link.spec"))
+
+	err := MirrorFileWithSymlinks(filepath.Join(src, "link.spec"), filepath.Join(dst, "link.spec"), false)
+	c.Assert(err, IsNil)
+	target, err := os.Readlink(filepath.Join(dst, "link.spec"))
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "first.spec")
+
+	err = MirrorFileWithSymlinks(filepath.Join(src, "link.spec"), filepath.Join(dst, "copy.spec"), true)
+	c.Assert(err, IsNil)
+	info, _ := os.Lstat(filepath.Join(dst, "copy.spec"))
+	c.Assert(info.Mode().IsRegular(), Equals, true)
+}
+
+func (s *MySuite) TestCopyDir(c *C) {
+	src := c.MkDir()
+	dst := filepath.Join(c.MkDir(), "copy")
+	os.MkdirAll(filepath.Join(src, "nested", "empty"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(src, "nested", "first.spec"), []byte("spec"), 0600)
+
+	err := CopyDir(src, dst)
+
+	c.Assert(err, IsNil)
+	c.Assert(DirExists(filepath.Join(dst, "nested", "empty")), Equals, true)
+	srcInfo, _ := os.Stat(filepath.Join(src, "nested", "first.spec"))
+	dstInfo, _ := os.Stat(filepath.Join(dst, "nested", "first.spec"))
+	c.Assert(dstInfo.Mode(), Equals, srcInfo.Mode())
+}
+
+func (s *MySuite) TestCopyDirFailsWhenDestinationHasConflictingFile(c *C) {
+	src := c.MkDir()
+	dst := c.MkDir()
+	os.MkdirAll(filepath.Join(src, "nested"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(dst, "nested"), []byte("file"), NewFilePermissions)
+
+	err := CopyDir(src, dst)
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestCopyDirFailsOnSymlinks(c *C) {
+	if isWindows() {
+		c.Skip("symlinks need elevated privileges on windows")
+	}
+	src := c.MkDir()
+	os.WriteFile(filepath.Join(src, "first.spec"), []byte("spec"), NewFilePermissions)
+	os.Symlink(filepath.Join(src, "first.spec"), filepath.Join(src, "link.spec"))
+
+	err := CopyDir(src, filepath.Join(c.MkDir(), "copy"))
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestSaveFilePreservesExistingPermissions(c *C) {
+	file := filepath.Join(c.MkDir(), "secret.properties")
+	os.WriteFile(file, []byte("old"), 0600)
+	before, _ := os.Stat(file)
+
+	err := SaveFile(file, "new", false)
+
+	c.Assert(err, IsNil)
+	after, _ := os.Stat(file)
+	c.Assert(after.Mode(), Equals, before.Mode())
+	contents, _ := ReadFileContents(file)
+	c.Assert(contents, Equals, "new")
+}
+
+func (s *MySuite) TestSaveFileReturningBackup(c *C) {
+	file := filepath.Join(c.MkDir(), "first.spec")
+	os.WriteFile(file, []byte("old"), NewFilePermissions)
+
+	backup, err := SaveFileReturningBackup(file, "new", true)
+	defer os.Remove(backup)
+
+	c.Assert(err, IsNil)
+	c.Assert(filepath.Dir(backup), Equals, filepath.Clean(os.TempDir()))
+	contents, _ := ReadFileContents(backup)
+	c.Assert(contents, Equals, "old")
+
+	backup, err = SaveFileReturningBackup(file, "newer", false)
+
+	c.Assert(err, IsNil)
+	c.Assert(backup, Equals, "")
+}
+
+func (s *MySuite) TestSaveFileWithMode(c *C) {
+	if isWindows() {
+		c.Skip("file permissions are not supported on windows")
+	}
+	file := filepath.Join(c.MkDir(), "secret.properties")
+	os.WriteFile(file, []byte("old"), NewFilePermissions)
+
+	err := SaveFileWithMode(file, "new", false, 0600)
+
+	c.Assert(err, IsNil)
+	info, _ := os.Stat(file)
+	c.Assert(info.Mode().Perm(), Equals, os.FileMode(0600))
+}
+
+func (s *MySuite) TestZipDirRoundTripsWithUnzipArchive(c *C) {
+	src := c.MkDir()
+	dest := c.MkDir()
+	os.MkdirAll(filepath.Join(src, "specs", "nested"), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Join(src, "empty"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(src, "specs", "nested", "first.spec"), []byte("# Specification"), NewFilePermissions)
+	zipFile := filepath.Join(c.MkDir(), "project.zip")
+
+	created, err := ZipDir(src, zipFile)
+	c.Assert(err, IsNil)
+	c.Assert(created, Equals, zipFile)
+
+	_, err = UnzipArchive(zipFile, dest)
+	c.Assert(err, IsNil)
+
+	c.Assert(DirExists(filepath.Join(dest, "empty")), Equals, true)
+	contents, _ := ReadFileContents(filepath.Join(dest, "specs", "nested", "first.spec"))
+	c.Assert(contents, Equals, "# Specification")
+	srcInfo, _ := os.Stat(filepath.Join(src, "specs", "nested", "first.spec"))
+	destInfo, _ := os.Stat(filepath.Join(dest, "specs", "nested", "first.spec"))
+	c.Assert(destInfo.Mode(), Equals, srcInfo.Mode())
+}
+
+func (s *MySuite) TestZipDirSkipsTargetZipInsideSourceDir(c *C) {
+	src := c.MkDir()
+	dest := c.MkDir()
+	os.WriteFile(filepath.Join(src, ManifestFile), []byte("{}"), NewFilePermissions)
+
+	zipFile, err := ZipDir(src, filepath.Join(src, "project.zip"))
+	c.Assert(err, IsNil)
+
+	extracted, err := UnzipArchiveVerbose(zipFile, dest)
+	c.Assert(err, IsNil)
+	c.Assert(extracted, DeepEquals, []string{ManifestFile})
+}
+
+func (s *MySuite) TestZipDirRemovesZipOnFailure(c *C) {
+	zipFile := filepath.Join(c.MkDir(), "project.zip")
+
+	_, err := ZipDir(filepath.Join(c.MkDir(), "missing"), zipFile)
+
+	c.Assert(err, NotNil)
+	c.Assert(FileExists(zipFile), Equals, false)
+}
+
+func (s *MySuite) TestUnzipArchiveVerbose(c *C) {
+	src := c.MkDir()
+	os.MkdirAll(filepath.Join(src, "specs"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(src, ManifestFile), []byte("{}"), NewFilePermissions)
+	os.WriteFile(filepath.Join(src, "specs", "first.spec"), []byte("# Specification"), NewFilePermissions)
+	zipFile, _ := ZipDir(src, filepath.Join(c.MkDir(), "project.zip"))
+
+	extracted, err := UnzipArchiveVerbose(zipFile, c.MkDir())
+
+	c.Assert(err, IsNil)
+	c.Assert(extracted, DeepEquals, []string{ManifestFile, filepath.Join("specs", "first.spec")})
+}
+
+func (s *MySuite) TestUnzipArchiveVerboseReturnsFilesExtractedBeforeFailure(c *C) {
+	zipFile, _ := filepath.Abs(filepath.Join("_testdata", "zipslip.zip"))
+
+	extracted, err := UnzipArchiveVerbose(zipFile, c.MkDir())
+
+	c.Assert(err, NotNil)
+	c.Assert(extracted, DeepEquals, []string{"good.txt"})
+}
+
+func (s *MySuite) TestUnzipArchiveWithProgress(c *C) {
+	src := c.MkDir()
+	os.MkdirAll(filepath.Join(src, "specs"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(src, ManifestFile), []byte("{}"), NewFilePermissions)
+	os.WriteFile(filepath.Join(src, "specs", "first.spec"), []byte("# Specification"), NewFilePermissions)
+	zipFile, _ := ZipDir(src, filepath.Join(c.MkDir(), "project.zip"))
+	dest := c.MkDir()
+	var names []string
+	var indices []int
+
+	unzipped, err := UnzipArchiveWithProgress(zipFile, dest, func(name string, index, total int) {
+		names = append(names, name)
+		indices = append(indices, index)
+		c.Assert(total, Equals, 3)
+	})
+
+	c.Assert(err, IsNil)
+	c.Assert(unzipped, Equals, dest)
+	c.Assert(names, DeepEquals, []string{ManifestFile, "specs/", "specs/first.spec"})
+	c.Assert(indices, DeepEquals, []int{1, 2, 3})
+	c.Assert(FileExists(filepath.Join(dest, "specs", "first.spec")), Equals, true)
+}
+
+func (s *MySuite) TestUnzipArchiveWithProgressStopsAtIllegalEntry(c *C) {
+	zipFile, _ := filepath.Abs(filepath.Join("_testdata", "zipslip.zip"))
+	var names []string
+
+	_, err := UnzipArchiveWithProgress(zipFile, c.MkDir(), func(name string, index, total int) {
+		names = append(names, name)
+	})
+
+	c.Assert(err, NotNil)
+	c.Assert(names, DeepEquals, []string{"good.txt"})
+}
+
+func (s *MySuite) TestUnzipArchiveRejectsEntriesOutsideDestination(c *C) {
+	root := c.MkDir()
+	dest := filepath.Join(root, "a", "b")
+	zipFile, _ := filepath.Abs(filepath.Join("_testdata", "zipslip.zip"))
+
+	_, err := UnzipArchive(zipFile, dest)
+
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "../../evil.txt"), Equals, true)
+	c.Assert(FileExists(filepath.Join(root, "evil.txt")), Equals, false)
+}
+
+func (s *MySuite) TestExtractArchiveForTarGz(c *C) {
+	archive := filepath.Join(c.MkDir(), "plugin.tar.gz")
+	createTarGz(archive, map[string]string{"bin/": "", "bin/runner": "#!/bin/sh"})
+	dest := c.MkDir()
+
+	_, err := ExtractArchive(archive, dest)
+
+	c.Assert(err, IsNil)
+	contents, _ := ReadFileContents(filepath.Join(dest, "bin", "runner"))
+	c.Assert(contents, Equals, "#!/bin/sh")
+	if !isWindows() {
+		info, _ := os.Stat(filepath.Join(dest, "bin", "runner"))
+		c.Assert(info.Mode().Perm(), Equals, os.FileMode(0755))
+	}
+}
+
+func (s *MySuite) TestExtractArchiveRejectsTarEntriesOutsideDestination(c *C) {
+	root := c.MkDir()
+	archive := filepath.Join(root, "plugin.tgz")
+	createTarGz(archive, map[string]string{"../evil.txt": "evil"})
+
+	_, err := ExtractArchive(archive, filepath.Join(root, "dest"))
+
+	c.Assert(err, NotNil)
+	c.Assert(FileExists(filepath.Join(root, "evil.txt")), Equals, false)
+}
+
+func (s *MySuite) TestExtractArchiveForUnsupportedFormat(c *C) {
+	_, err := ExtractArchive("plugin.rar", c.MkDir())
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestGzipFileRoundTrip(c *C) {
+	dir := c.MkDir()
+	src := filepath.Join(dir, "gauge.log")
+	contents := []byte(strings.Repeat("gauge log line\n", 100))
+	os.WriteFile(src, contents, 0600)
+
+	c.Assert(GzipFile(src, src+".gz"), IsNil)
+	c.Assert(GunzipFile(src+".gz", filepath.Join(dir, "restored.log")), IsNil)
+
+	restored, _ := os.ReadFile(filepath.Join(dir, "restored.log"))
+	c.Assert(restored, DeepEquals, contents)
+	srcInfo, _ := os.Stat(src)
+	gzInfo, _ := os.Stat(src + ".gz")
+	restoredInfo, _ := os.Stat(filepath.Join(dir, "restored.log"))
+	c.Assert(gzInfo.Mode(), Equals, srcInfo.Mode())
+	c.Assert(restoredInfo.Mode(), Equals, srcInfo.Mode())
+}
+
+func (s *MySuite) TestGunzipFileWithInvalidArchive(c *C) {
+	dir := c.MkDir()
+	src := filepath.Join(dir, "gauge.log.gz")
+	os.WriteFile(src, []byte("not gzipped"), NewFilePermissions)
+
+	err := GunzipFile(src, filepath.Join(dir, "gauge.log"))
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestUrlExists(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/plugin.zip", http.StatusFound)
+		case "/plugin.zip":
+			w.WriteHeader(http.StatusOK)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	exists, err := UrlExists(server.URL + "/plugin.zip")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+
+	exists, err = UrlExists(server.URL + "/redirect")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+
+	exists, err = UrlExists(server.URL + "/missing.zip")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+
+	exists, err = UrlExists(server.URL + "/broken")
+	c.Assert(err, NotNil)
+	c.Assert(exists, Equals, false)
+}
+
+func (s *MySuite) TestUrlExistsWithTimeout(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	exists, err := UrlExistsWithTimeout(server.URL, 10*time.Millisecond)
+
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true)
+	c.Assert(exists, Equals, false)
+}
+
+func (s *MySuite) TestDownloadToFile(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plugin.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("gauge"))
+	}))
+	defer server.Close()
+	dest := filepath.Join(c.MkDir(), "nested", "java-1.0.0.zip")
+
+	err := DownloadToFile(server.URL+"/plugin.zip?version=1.0.0", dest)
+
+	c.Assert(err, IsNil)
+	contents, _ := ReadFileContents(dest)
+	c.Assert(contents, Equals, "gauge")
+	entries, _ := os.ReadDir(filepath.Dir(dest))
+	c.Assert(len(entries), Equals, 1)
+}
+
+func (s *MySuite) TestDownload(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("gauge"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	file, err := Download(server.URL+"/plugins/java.zip?version=1.0.0", dir)
+
+	c.Assert(err, IsNil)
+	c.Assert(file, Equals, filepath.Join(dir, "java.zip"))
+	contents, _ := ReadFileContents(file)
+	c.Assert(contents, Equals, "gauge")
+}
+
+func (s *MySuite) TestDownloadWithContextRemovesPartialDownloadWhenCancelled(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		w.Write([]byte("gau"))
+		w.(http.Flusher).Flush()
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	_, err := DownloadWithContext(ctx, server.URL+"/java.zip", dir)
+
+	c.Assert(errors.Is(err, context.Canceled), Equals, true)
+	entries, _ := os.ReadDir(dir)
+	c.Assert(len(entries), Equals, 0)
+}
+
+func (s *MySuite) TestDownloadWithProgress(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(3*copyBufferSize))
+		w.Write(bytes.Repeat([]byte("a"), 3*copyBufferSize))
+	}))
+	defer server.Close()
+	dest := filepath.Join(c.MkDir(), "java.zip")
+	var calls [][2]int64
+
+	err := DownloadWithProgress(server.URL+"/java.zip", dest, func(bytesWritten, totalBytes int64) {
+		calls = append(calls, [2]int64{bytesWritten, totalBytes})
+	})
+
+	c.Assert(err, IsNil)
+	c.Assert(len(calls) >= 1 && len(calls) < 4, Equals, true)
+	c.Assert(calls[len(calls)-1], Equals, [2]int64{3 * copyBufferSize, 3 * copyBufferSize})
+}
+
+func (s *MySuite) TestDownloadWithProgressWithUnknownSize(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("gau"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("ge"))
+	}))
+	defer server.Close()
+	dest := filepath.Join(c.MkDir(), "java.zip")
+	var last [2]int64
+
+	err := DownloadWithProgress(server.URL+"/java.zip", dest, func(bytesWritten, totalBytes int64) {
+		last = [2]int64{bytesWritten, totalBytes}
+	})
+
+	c.Assert(err, IsNil)
+	c.Assert(last, Equals, [2]int64{5, -1})
+}
+
+func (s *MySuite) TestDownloadWithRetry(c *C) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("gauge"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	file, err := DownloadWithRetry(server.URL+"/java.zip", dir, 3, time.Millisecond)
+
+	c.Assert(err, IsNil)
+	c.Assert(requests, Equals, 3)
+	contents, _ := ReadFileContents(file)
+	c.Assert(contents, Equals, "gauge")
+}
+
+func (s *MySuite) TestDownloadWithRetryGivesUpAfterMaxAttempts(c *C) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Length", "10")
+		w.Write([]byte("gau"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	_, err := DownloadWithRetry(server.URL+"/java.zip", dir, 2, time.Millisecond)
+
+	c.Assert(err, ErrorMatches, "Failed to download .*/java.zip after 2 attempt\\(s\\): .*")
+	c.Assert(requests, Equals, 2)
+	entries, _ := os.ReadDir(dir)
+	c.Assert(len(entries), Equals, 0)
+}
+
+func (s *MySuite) TestDownloadWithRetryDoesNotRetryNotFound(c *C) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := DownloadWithRetry(server.URL+"/java.zip", c.MkDir(), 3, time.Millisecond)
+
+	c.Assert(err, ErrorMatches, "Failed to download .*/java.zip after 1 attempt\\(s\\): .*404 Not Found")
+	c.Assert(requests, Equals, 1)
+}
+
+func (s *MySuite) TestDownloadAndVerify(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("gauge"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	file, err := DownloadAndVerify(server.URL+"/plugins/java.zip?version=1.0.0", dir, "A90FD9A9A1E66597AE124F542F73AC08D3112E7D6F5E1781163BE07CCAE5BE0D")
+
+	c.Assert(err, IsNil)
+	c.Assert(file, Equals, filepath.Join(dir, "java.zip"))
+	contents, _ := ReadFileContents(file)
+	c.Assert(contents, Equals, "gauge")
+}
+
+func (s *MySuite) TestDownloadAndVerifyWithChecksumMismatch(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	_, err := DownloadAndVerify(server.URL+"/java.zip", dir, "a90fd9a9a1e66597ae124f542f73ac08d3112e7d6f5e1781163be07ccae5be0d")
+
+	c.Assert(err, ErrorMatches, "Checksum mismatch for .*/java.zip: expected a90fd9a9a1e66597ae124f542f73ac08d3112e7d6f5e1781163be07ccae5be0d, got [0-9a-f]{64}")
+	c.Assert(FileExists(filepath.Join(dir, "java.zip")), Equals, false)
+}
+
+func (s *MySuite) TestDownloadAndVerifyRejectsUrlsEscapingTargetDir(c *C) {
+	dir := c.MkDir()
+
+	for _, u := range []string{"http://localhost/plugins/%2e%2e", "http://localhost/..%5cjava.zip", "http://localhost/", "http://localhost/%2e"} {
+		_, err := DownloadAndVerify(u, dir, "")
+		c.Assert(err, ErrorMatches, "Invalid url .*: no file name in path", Commentf(u))
+	}
+}
+
+func (s *MySuite) TestDownloadAndVerifyWithChecksumMismatchKeepsExistingFile(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+	existing := filepath.Join(dir, "java.zip")
+	os.WriteFile(existing, []byte("gauge"), NewFilePermissions)
+
+	_, err := DownloadAndVerify(server.URL+"/java.zip", dir, "a90fd9a9a1e66597ae124f542f73ac08d3112e7d6f5e1781163be07ccae5be0d")
+
+	c.Assert(err, NotNil)
+	contents, _ := ReadFileContents(existing)
+	c.Assert(contents, Equals, "gauge")
+	entries, _ := os.ReadDir(dir)
+	c.Assert(len(entries), Equals, 1)
+}
+
+func (s *MySuite) TestDownloadToFileWithErrorResponse(c *C) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	dir := c.MkDir()
+	dest := filepath.Join(dir, "plugin.zip")
+
+	err := DownloadToFile(server.URL+"/plugin.zip", dest)
+
+	c.Assert(err, ErrorMatches, "Failed to download .*/plugin.zip, 404-404 Not Found")
+	c.Assert(FileExists(dest), Equals, false)
+	entries, _ := os.ReadDir(dir)
+	c.Assert(len(entries), Equals, 0)
+}
+
+func (s *MySuite) TestDownloadToFileRemovesPartialDownload(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		w.Write([]byte("gau"))
+	}))
+	defer server.Close()
+	dir := c.MkDir()
+
+	err := DownloadToFile(server.URL+"/plugin.zip", filepath.Join(dir, "plugin.zip"))
+
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, io.ErrUnexpectedEOF), Equals, true)
+	entries, _ := os.ReadDir(dir)
+	c.Assert(len(entries), Equals, 0)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func (s *MySuite) TestSetHTTPClient(c *C) {
+	defer SetHTTPClient(nil)
+	var requested string
+	SetHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requested = r.URL.String()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})})
+
+	exists, err := UrlExists("https://downloads.gauge.org/plugin.zip")
+
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+	c.Assert(requested, Equals, "https://downloads.gauge.org/plugin.zip")
+}
+
+func (s *MySuite) TestSetHTTPClientWithCustomCA(c *C) {
+	defer SetHTTPClient(nil)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plugin.zip" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	_, err := UrlExists(server.URL + "/plugin.zip")
+	c.Assert(err, NotNil)
+
+	SetHTTPClient(server.Client())
+
+	exists, err := UrlExists(server.URL + "/plugin.zip")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+
+	exists, err = UrlExists(server.URL + "/missing.zip")
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+}
+
+func (s *MySuite) TestDefaultHTTPClient(c *C) {
+	SetHTTPClient(nil)
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+
+	c.Assert(ok, Equals, true)
+	c.Assert(transport.Proxy, NotNil)
+	c.Assert(transport.ResponseHeaderTimeout, Equals, defaultURLTimeout)
+}
+
+func createTarGz(archive string, entries map[string]string) {
+	f, _ := os.Create(archive)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0755})
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(entries[name]))})
+		tw.Write([]byte(entries[name]))
+	}
+}
+
+func (s *MySuite) TestGetPluginInstallPrefixesPrefersUserInstalls(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+
+	prefixes, err := GetPluginInstallPrefixes()
+
+	c.Assert(err, IsNil)
+	c.Assert(prefixes[0], Equals, filepath.Join(gaugeHome, Plugins))
+}
+
+func (s *MySuite) TestPluginInstallPath(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "html-report", "4.0.0"), NewDirectoryPermissions)
+
+	path, installed := PluginInstallPath("html-report", "4.0.0")
+	c.Assert(installed, Equals, true)
+	c.Assert(path, Equals, filepath.Join(gaugeHome, Plugins, "html-report", "4.0.0"))
+	c.Assert(IsPluginInstalled("html-report", "4.0.0"), Equals, true)
+
+	_, installed = PluginInstallPath("html-report", "5.0.0")
+	c.Assert(installed, Equals, false)
+
+	_, installed = PluginInstallPath("xml-report", "1.0.0")
+	c.Assert(installed, Equals, false)
+}
+
+func (s *MySuite) TestSubDirectoryExistsFold(c *C) {
+	pluginsDir := c.MkDir()
+	os.MkdirAll(filepath.Join(pluginsDir, "Html-Report"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(pluginsDir, "java"), []byte(""), NewFilePermissions)
+
+	c.Assert(SubDirectoryExists(pluginsDir, "html-report"), Equals, false)
+	c.Assert(SubDirectoryExistsFold(pluginsDir, "html-report"), Equals, true)
+	c.Assert(SubDirectoryExistsFold(pluginsDir, "HTML-REPORT"), Equals, true)
+	c.Assert(SubDirectoryExistsFold(pluginsDir, "java"), Equals, false)
+	c.Assert(SubDirectoryExistsFold(pluginsDir, "xml-report"), Equals, false)
+}
+
+func (s *MySuite) TestIsPluginInstalledWithMismatchedCase(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "Html-Report", "4.0.0"), NewDirectoryPermissions)
+
+	c.Assert(IsPluginInstalled("html-report", "4.0.0"), Equals, isCaseInsensitiveOS())
+}
+
+func (s *MySuite) TestUninstallPlugin(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "java", "0.9.0"), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "java", "0.10.0"), NewDirectoryPermissions)
+
+	err := UninstallPlugin("java", "0.9.0")
+
+	c.Assert(err, IsNil)
+	c.Assert(IsPluginInstalled("java", "0.9.0"), Equals, false)
+	c.Assert(IsPluginInstalled("java", "0.10.0"), Equals, true)
+}
+
+func (s *MySuite) TestUninstallPluginRejectsInvalidVersions(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "java", "0.9.0"), NewDirectoryPermissions)
+
+	c.Assert(UninstallPlugin("java", ""), NotNil)
+	c.Assert(UninstallPlugin("java", ".."), NotNil)
+	c.Assert(UninstallPlugin("java", "1.0.0"), NotNil)
+	c.Assert(DirExists(filepath.Join(gaugeHome, Plugins, "java")), Equals, true)
+}
+
+func (s *MySuite) TestPluginNotInstalledErrorsWrapErrPluginNotInstalled(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "java", "0.9.0"), NewDirectoryPermissions)
+
+	_, err := GetPluginsInstallDir("ruby")
+	c.Assert(errors.Is(err, ErrPluginNotInstalled), Equals, true)
+	c.Assert(err, ErrorMatches, "Plugin 'ruby' not installed on following locations : .*")
+
+	err = UninstallPlugin("java", "1.0.0")
+	c.Assert(errors.Is(err, ErrPluginNotInstalled), Equals, true)
+	c.Assert(err, ErrorMatches, "Plugin 'java' version '1.0.0' is not installed")
+}
+
+func (s *MySuite) TestListInstalledPlugins(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	pluginsDir := filepath.Join(gaugeHome, Plugins)
+	os.MkdirAll(filepath.Join(pluginsDir, "java", "0.9.0"), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Join(pluginsDir, "java", "0.10.0"), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Join(pluginsDir, "html-report", "4.0.0"), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Join(pluginsDir, "html-report", ".tmp"), NewDirectoryPermissions)
+	os.MkdirAll(filepath.Join(pluginsDir, ".cache"), NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(pluginsDir, "install.log"), []byte(""), NewFilePermissions)
+
+	plugins, err := ListInstalledPlugins()
+
+	c.Assert(err, IsNil)
+	c.Assert(plugins, DeepEquals, map[string][]string{
+		"java":        {"0.10.0", "0.9.0"},
+		"html-report": {"4.0.0"},
+	})
+}
+
+func (s *MySuite) TestGetLatestInstalledPluginVersion(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	for _, v := range []string{"1.9.0", "1.10.0", "1.10.0-beta", "nightly"} {
+		os.MkdirAll(filepath.Join(gaugeHome, Plugins, "java", v), NewDirectoryPermissions)
+	}
+	os.MkdirAll(filepath.Join(gaugeHome, Plugins, "ruby", "nightly"), NewDirectoryPermissions)
+
+	latest, err := GetLatestInstalledPluginVersion("java")
+	c.Assert(err, IsNil)
+	c.Assert(latest, Equals, "1.10.0")
+
+	_, err = GetLatestInstalledPluginVersion("ruby")
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestVersionCompare(c *C) {
+	compare := func(a, b string) int {
+		va, _ := parseVersion(a)
+		vb, _ := parseVersion(b)
+		return va.compare(vb)
+	}
+
+	c.Assert(compare("1.10.0", "1.9.0"), Equals, 1)
+	c.Assert(compare("1.0.0", "1.0.0"), Equals, 0)
+	c.Assert(compare("1.0.0-beta", "1.0.0"), Equals, -1)
+	c.Assert(compare("2.0.0", "10.0.0"), Equals, -1)
+	c.Assert(compare("1.0.0-beta.10", "1.0.0-beta.2"), Equals, 1)
+	c.Assert(compare("1.0.0-alpha", "1.0.0-alpha.1"), Equals, -1)
+	c.Assert(compare("1.0.0-alpha.1", "1.0.0-alpha.beta"), Equals, -1)
+	c.Assert(compare("1.0.0-beta", "1.0.0-alpha.1"), Equals, 1)
+	c.Assert(compare("1.0.0-rc.1", "1.0.0-rc.1"), Equals, 0)
+	_, err := parseVersion("1.0")
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestValidatePluginProperties(c *C) {
+	c.Assert(ValidatePluginProperties(map[string]interface{}{"id": "java", "version": "0.10.0"}), IsNil)
+	c.Assert(ValidatePluginProperties(nil), NotNil)
+
+	err := ValidatePluginProperties(map[string]interface{}{"id": "java"})
+	c.Assert(err, ErrorMatches, ".*version field missing")
+
+	err = ValidatePluginProperties(map[string]interface{}{"id": "java", "version": 1.0})
+	c.Assert(err, ErrorMatches, ".*version is not a string")
+}
+
+func (s *MySuite) TestGetPluginPropertiesForNonObjectJSON(c *C) {
+	file := filepath.Join(c.MkDir(), PluginJSONFile)
+	os.WriteFile(file, []byte(`["java"]`), NewFilePermissions)
+
+	_, err := GetPluginProperties(file)
+
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestGetGaugePluginVersion(c *C) {
+	os.Chdir(c.MkDir())
+	os.WriteFile("java.json", []byte(`{"id": "java", "version": "0.10.0"}`), NewFilePermissions)
+
+	version, err := GetGaugePluginVersion("java")
+
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, "0.10.0")
+}
+
+func (s *MySuite) TestGetGaugePluginVersionFromInstalledPlugin(c *C) {
+	gaugeHome := c.MkDir()
+	os.Setenv(GaugeHome, gaugeHome)
+	defer os.Setenv(GaugeHome, "")
+	pluginDir := filepath.Join(gaugeHome, Plugins, "java", "0.10.0")
+	os.MkdirAll(pluginDir, NewDirectoryPermissions)
+	os.WriteFile(filepath.Join(pluginDir, "java.json"), []byte(`{"id": "java", "version": "0.10.0"}`), NewFilePermissions)
+	os.Chdir(c.MkDir())
+
+	version, err := GetGaugePluginVersion("java")
+
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, "0.10.0")
+}
+
+func (s *MySuite) TestGetGaugePluginVersionFromDir(c *C) {
+	pluginDir := c.MkDir()
+	os.WriteFile(filepath.Join(pluginDir, "java.json"), []byte(`{"id": "java", "version": "0.10.0"}`), NewFilePermissions)
+
+	version, err := GetGaugePluginVersionFromDir(pluginDir, "java")
+
+	c.Assert(err, IsNil)
+	c.Assert(version, Equals, "0.10.0")
+}
+
+func (s *MySuite) TestGetGaugePluginVersionWithNumericVersion(c *C) {
+	os.Chdir(c.MkDir())
+	os.WriteFile("java.json", []byte(`{"id": "java", "version": 1.0}`), NewFilePermissions)
+
+	_, err := GetGaugePluginVersion("java")
+
+	c.Assert(err, ErrorMatches, ".*version is not a string")
+}
+
+func (s *MySuite) TestGetGaugePluginVersionWithoutVersion(c *C) {
+	os.Chdir(c.MkDir())
+	os.WriteFile("java.json", []byte(`{"id": "java"}`), NewFilePermissions)
+
+	_, err := GetGaugePluginVersion("java")
+
+	c.Assert(err, ErrorMatches, ".*version field missing")
 }
 
 func getAbsPath(path string) string {
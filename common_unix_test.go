@@ -0,0 +1,60 @@
+//go:build !windows
+
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestExecuteCommandStaysInCallersProcessGroup(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+
+	cmd, err := ExecuteCommand(helperCommand("out", "err", "0", "10s"), "", io.Discard, io.Discard)
+	c.Assert(err, IsNil)
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	c.Assert(err, IsNil)
+	c.Assert(pgid, Equals, syscall.Getpgrp())
+}
+
+func (s *MySuite) TestExecuteCommandInProcessGroup(c *C) {
+	os.Setenv(helperProcessEnv, "1")
+	defer os.Unsetenv(helperProcessEnv)
+
+	cmd, err := ExecuteCommandInProcessGroup(helperCommand("out", "err", "0", "10s"), "", io.Discard, io.Discard)
+	c.Assert(err, IsNil)
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	c.Assert(err, IsNil)
+	c.Assert(pgid, Equals, cmd.Process.Pid)
+}
+
+func (s *MySuite) TestWaitWithTimeoutWhenSpawnedProcessHoldsOutputOpen(c *C) {
+	var out bytes.Buffer
+	cmd, err := ExecuteCommand([]string{"sh", "-c", "sleep 5 & sleep 5; wait"}, "", &out, &out)
+	c.Assert(err, IsNil)
+
+	start := time.Now()
+	err = WaitWithTimeout(cmd, 200*time.Millisecond)
+
+	c.Assert(errors.Is(err, ErrCommandTimeout), Equals, true)
+	c.Assert(time.Since(start) < 2*time.Second, Equals, true)
+}
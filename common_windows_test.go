@@ -0,0 +1,42 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package common
+
+import (
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestGetExecutableCommandResolvesExeExtensionOnWindows(c *C) {
+	cmd := GetExecutableCommand(false, "cmd", "/c", "echo")
+
+	c.Assert(filepath.IsAbs(cmd.Path), Equals, true)
+	c.Assert(strings.EqualFold(filepath.Base(cmd.Path), "cmd.exe"), Equals, true)
+	c.Assert(cmd.Args, DeepEquals, []string{"cmd", "/c", "echo"})
+}
+
+func (s *MySuite) TestPathToFileURLOnWindows(c *C) {
+	uri, err := PathToFileURL(`C:\Users\gauge\my specs\a.spec`)
+	c.Assert(err, IsNil)
+	c.Assert(uri, Equals, "file:///C:/Users/gauge/my%20specs/a.spec")
+
+	uri, err = PathToFileURL(`\\server\share\specs\a.spec`)
+	c.Assert(err, IsNil)
+	c.Assert(uri, Equals, "file://server/share/specs/a.spec")
+}
+
+func (s *MySuite) TestFileURLToPathOnWindows(c *C) {
+	path, err := FileURLToPath("file:///c%3A/Users/gauge/my%20specs/a.spec")
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, `c:\Users\gauge\my specs\a.spec`)
+
+	path, err = FileURLToPath("file://server/share/specs/a.spec")
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, `\\server\share\specs\a.spec`)
+}
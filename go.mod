@@ -4,6 +4,7 @@ go 1.23
 
 require (
 	github.com/dmotylev/goproperties v0.0.0-20140630191356-7cbffbaada47
+	golang.org/x/text v0.21.0
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 )
 